@@ -0,0 +1,173 @@
+// Package clavtest runs tests written in clav. Test files are named
+// *_test.clav and every top level function whose name starts with test_ is
+// a test. Each test runs in a fresh Interpreter, which first executes the
+// whole file and then calls the test function.
+package clavtest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/pkg"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+const (
+	FileSuffix = "_test.clav"
+	TestPrefix = "test_"
+)
+
+type Options struct {
+	// Filter selects the tests to run by name. Nil runs every test.
+	Filter *regexp.Regexp
+	// Interpreter is applied to the Interpreter of every test.
+	Interpreter []interpreter.Option
+	// File, when set, returns further options for the tests in the file at
+	// path, such as the packages of the project it belongs to.
+	File func(path string) []interpreter.Option
+}
+
+// Result is the outcome of one test.
+type Result struct {
+	File     string
+	Name     string
+	Duration time.Duration
+	// Err is nil when the test passed.
+	Err error
+	// SourceFile, Line and Source locate the failure when it is known. The
+	// file is File itself or a module the test imported.
+	SourceFile string
+	Line       int
+	Source     string
+	// Output is everything the test printed.
+	Output string
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Discover returns the test files in paths. Directories are searched
+// recursively, skipping vendored packages; files are used as given.
+func Discover(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == pkg.VendorDir {
+				return filepath.SkipDir
+			}
+			if d.Type().IsRegular() && (p == path || strings.HasSuffix(p, FileSuffix)) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// RunFile runs the tests declared in the file at path. The returned error
+// reports files that could not be read or parsed.
+func RunFile(ctx context.Context, path string, opts Options) ([]Result, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := parse(string(source))
+	if err != nil {
+		return nil, err
+	}
+	if opts.File != nil {
+		opts.Interpreter = append(opts.Interpreter[:len(opts.Interpreter):len(opts.Interpreter)], opts.File(path)...)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]string{abs: strings.Split(string(source), "\n")}
+	var results []Result
+	for _, stmt := range statements {
+		fn, ok := stmt.(parser.Function)
+		if !ok || !strings.HasPrefix(fn.Name.Lexeme, TestPrefix) {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter.MatchString(fn.Name.Lexeme) {
+			continue
+		}
+		result := runTest(ctx, path, statements, fn.Name.Lexeme, opts)
+		result.Source = sourceLine(files, result.SourceFile, result.Line)
+		if result.SourceFile == abs {
+			result.SourceFile = path
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runTest(ctx context.Context, path string, statements []parser.Stmt, name string, opts Options) Result {
+	var output strings.Builder
+	iopts := append(opts.Interpreter[:len(opts.Interpreter):len(opts.Interpreter)],
+		interpreter.WithScriptPath(path),
+		interpreter.WithStdout(&output))
+	inter := interpreter.NewInterpreter(iopts...)
+	start := time.Now()
+	err := inter.InterpretContext(ctx, statements)
+	if err == nil {
+		_, err = inter.Call(ctx, name)
+	}
+	result := Result{File: path, Name: name, Duration: time.Since(start), Err: err, Output: output.String()}
+	var runtimeErr interpreter.InterpreterError
+	if errors.As(err, &runtimeErr) {
+		result.SourceFile = runtimeErr.File()
+		result.Line = runtimeErr.Line()
+	}
+	return result
+}
+
+// sourceLine returns the trimmed text of a line of file, caching the lines
+// of every file read in files. Unknown files and lines give "".
+func sourceLine(files map[string][]string, file string, line int) string {
+	if file == "" {
+		return ""
+	}
+	lines, ok := files[file]
+	if !ok {
+		if source, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(source), "\n")
+		}
+		files[file] = lines
+	}
+	if line > 0 && line <= len(lines) {
+		return strings.TrimSpace(lines[line-1])
+	}
+	return ""
+}
+
+func parse(source string) ([]parser.Stmt, error) {
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	statements, errs := p.Parse()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	return statements, nil
+}
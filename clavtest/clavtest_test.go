@@ -0,0 +1,129 @@
+package clavtest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/clavtest"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/pkg"
+)
+
+const source = `fun double(x) { return x * 2; }
+
+fun test_pass() {
+  assert.equal(double(2), 4);
+}
+
+fun test_fail() {
+  assert.equal(double(2), 5);
+}
+
+fun helper() {}
+`
+
+func TestRunFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "double_test.clav")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files, err := clavtest.Discover([]string{dir})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("discovered %v, %v", files, err)
+	}
+
+	results, err := clavtest.RunFile(context.Background(), path, clavtest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !results[0].Passed() || results[0].Name != "test_pass" {
+		t.Errorf("test_pass: %+v", results[0])
+	}
+	fail := results[1]
+	if fail.Passed() || fail.SourceFile != path || fail.Line != 8 || fail.Source != "assert.equal(double(2), 5);" {
+		t.Errorf("test_fail: %+v", fail)
+	}
+
+	var junit strings.Builder
+	if err := clavtest.WriteJUnit(&junit, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(junit.String(), `tests="2" failures="1"`) {
+		t.Errorf("unexpected report:\n%s", junit.String())
+	}
+
+	filtered, err := clavtest.RunFile(context.Background(), path, clavtest.Options{
+		Filter: regexp.MustCompile("pass"),
+	})
+	if err != nil || len(filtered) != 1 {
+		t.Errorf("filter ran %d tests, %v", len(filtered), err)
+	}
+}
+
+// TestSourceInModule checks that a failure raised in an imported module is
+// located in that module rather than in the test file.
+func TestSourceInModule(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib.clav")
+	path := filepath.Join(dir, "lib_test.clav")
+	for file, text := range map[string]string{
+		lib:  "fun broken() {\n  return missing;\n}\n",
+		path: "import \"lib.clav\";\nfun test_broken() {\n  lib.broken();\n}\n",
+	} {
+		if err := os.WriteFile(file, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := clavtest.RunFile(context.Background(), path, clavtest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if r := results[0]; r.Passed() || r.SourceFile != lib || r.Line != 2 || r.Source != "return missing;" {
+		t.Errorf("test_broken: %+v", r)
+	}
+}
+
+func TestPackages(t *testing.T) {
+	dir := t.TempDir()
+	vendored := filepath.Join(dir, pkg.VendorDir, "greet")
+	if err := os.MkdirAll(vendored, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for path, text := range map[string]string{
+		filepath.Join(vendored, "greet.clav"):      `fun hello() { return "hello"; }`,
+		filepath.Join(vendored, "greet_test.clav"): `fun test_vendored() { assert.equal(1, 2); }`,
+		filepath.Join(dir, "main_test.clav"):       "import \"greet/greet.clav\";\nfun test_hello() { assert.equal(greet.hello(), \"hello\"); }",
+	} {
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := clavtest.Discover([]string{dir})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("discovered %v, %v", files, err)
+	}
+
+	packages := map[string]string{"greet": vendored}
+	results, err := clavtest.RunFile(context.Background(), files[0], clavtest.Options{
+		File: func(string) []interpreter.Option {
+			return []interpreter.Option{interpreter.WithPackages(packages)}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Passed() {
+		t.Errorf("results: %+v", results)
+	}
+}
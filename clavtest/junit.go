@@ -0,0 +1,85 @@
+package clavtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as JUnit XML, one test suite per file.
+func WriteJUnit(w io.Writer, results []Result) error {
+	var doc junitSuites
+	index := map[string]int{}
+	var durations []time.Duration
+	for _, r := range results {
+		j, ok := index[r.File]
+		if !ok {
+			j = len(doc.Suites)
+			index[r.File] = j
+			doc.Suites = append(doc.Suites, junitSuite{Name: r.File})
+			durations = append(durations, 0)
+		}
+		suite := &doc.Suites[j]
+		c := junitCase{
+			Name:      r.Name,
+			Classname: r.File,
+			Time:      seconds(r.Duration.Seconds()),
+			SystemOut: r.Output,
+		}
+		if !r.Passed() {
+			suite.Failures++
+			body := r.Err.Error()
+			if r.Source != "" {
+				body = fmt.Sprintf("%s:%d: %s\n%s", r.SourceFile, r.Line, r.Source, body)
+			}
+			c.Failure = &junitFailure{Message: r.Err.Error(), Body: body}
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+		durations[j] += r.Duration
+	}
+	for j := range doc.Suites {
+		doc.Suites[j].Time = seconds(durations[j].Seconds())
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,128 @@
+package main_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+// The conformance suite runs every .clav file below testdata, except the
+// modules imported by other scripts, which live in directories named lib. A
+// script states what it prints with `// expect: <line>` comments and the
+// errors it fails with using `// expect error: <message>`, both in order.
+var (
+	expectOutput = regexp.MustCompile(`// expect: ?(.*)$`)
+	expectError  = regexp.MustCompile(`// expect error: ?(.*)$`)
+)
+
+func TestConformance(t *testing.T) {
+	root, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scripts []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "lib" {
+			return filepath.SkipDir
+		}
+		if filepath.Ext(path) == ".clav" {
+			scripts = append(scripts, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) == 0 {
+		t.Fatal("no scripts found in testdata")
+	}
+	for _, script := range scripts {
+		name, _ := filepath.Rel(root, script)
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			t.Parallel()
+			runConformance(t, root, script)
+		})
+	}
+}
+
+func runConformance(t *testing.T, root, script string) {
+	data, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := string(data)
+	var wantOutput, wantErrors []string
+	for _, line := range strings.Split(source, "\n") {
+		if m := expectError.FindStringSubmatch(line); m != nil {
+			wantErrors = append(wantErrors, m[1])
+		} else if m := expectOutput.FindStringSubmatch(line); m != nil {
+			wantOutput = append(wantOutput, m[1])
+		}
+	}
+
+	var output strings.Builder
+	tmp := t.TempDir()
+	gotErrors := interpret(source,
+		interpreter.WithStdout(&output),
+		interpreter.WithScriptPath(script),
+		interpreter.WithArgs([]string{tmp}),
+		interpreter.WithSeed(1),
+		interpreter.WithClock(interpreter.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))),
+		interpreter.WithCapabilities(interpreter.Capabilities{
+			ReadRoots:  []string{root, tmp},
+			WriteRoots: []string{tmp},
+			Time:       true,
+		}),
+	)
+	gotOutput := strings.Split(output.String(), "\n")
+	gotOutput = gotOutput[:len(gotOutput)-1]
+
+	compare(t, "output", wantOutput, gotOutput)
+	compare(t, "error", wantErrors, gotErrors)
+}
+
+// interpret runs source the way the CLI does and returns its errors.
+func interpret(source string, opts ...interpreter.Option) []string {
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs == nil {
+		p := parser.NewParser(tokens)
+		var statements []parser.Stmt
+		statements, errs = p.Parse()
+		if errs == nil {
+			if err := interpreter.NewInterpreter(opts...).Interpret(statements); err != nil {
+				errs = []error{err}
+			}
+		}
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return messages
+}
+
+func compare(t *testing.T, kind string, want, got []string) {
+	t.Helper()
+	for i := range max(len(want), len(got)) {
+		switch {
+		case i >= len(got):
+			t.Errorf("missing %s line %d: want %q", kind, i+1, want[i])
+		case i >= len(want):
+			t.Errorf("unexpected %s line %d: %q", kind, i+1, got[i])
+		case want[i] != got[i]:
+			t.Errorf("%s line %d: want %q, got %q", kind, i+1, want[i], got[i])
+		}
+	}
+}
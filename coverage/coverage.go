@@ -0,0 +1,142 @@
+// Package coverage records which lines and branches of clav programs ran
+// and reports them as a text summary, an HTML page or LCOV.
+//
+// An Interpreter created with interpreter.WithCoverage registers every file
+// it runs with the Profile, which marks the lines holding statements and the
+// branch points of the file as not yet run, and then counts the statements
+// and branches it executes.
+package coverage
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/token"
+)
+
+// Arms of the branch recorded for a try statement.
+const (
+	TryCompleted = iota
+	TryCaught
+)
+
+// Profile holds the coverage of every file registered with it.
+type Profile struct {
+	files map[string]*File
+}
+
+// File is the coverage of one source file.
+type File struct {
+	// Path is the absolute path of the file, or empty for a script not read
+	// from a file.
+	Path string
+	// Lines maps each line on which a statement starts to the number of
+	// times such a statement ran.
+	Lines map[int]int
+	// Branches lists the places where execution takes one of several
+	// paths, in source order.
+	Branches []*Branch
+
+	branches map[int]*Branch
+}
+
+// Branch is a statement that continues along one of several arms.
+type Branch struct {
+	Line int
+	// Taken counts how often each arm was taken.
+	Taken []int
+
+	offset int
+}
+
+func New() *Profile {
+	return &Profile{files: map[string]*File{}}
+}
+
+// Register records the statements and branches of a file as not yet run.
+// Files already registered are left alone.
+func (p *Profile) Register(path string, stmts []parser.Stmt) {
+	if _, ok := p.files[path]; ok {
+		return
+	}
+	f := &File{Path: path, Lines: map[int]int{}, branches: map[int]*Branch{}}
+	f.register(stmts)
+	slices.SortFunc(f.Branches, func(a, b *Branch) int { return cmp.Compare(a.offset, b.offset) })
+	p.files[path] = f
+}
+
+func (f *File) register(stmts []parser.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case parser.Block:
+			f.register(s.Statements)
+			continue
+		case parser.Try:
+			f.register(s.Body)
+			f.register(s.Handler)
+			branch := &Branch{Line: s.Keyword.Line, Taken: make([]int, 2), offset: s.Keyword.Offset}
+			f.branches[branch.offset] = branch
+			f.Branches = append(f.Branches, branch)
+		case parser.Function:
+			f.register(s.Body)
+		case parser.Expression, parser.Print, parser.Var, parser.Return, parser.Import:
+		}
+		f.Lines[parser.StmtToken(stmt).Line] = 0
+	}
+}
+
+// Statement counts a statement starting on line of the file at path.
+func (p *Profile) Statement(path string, line int) {
+	if f, ok := p.files[path]; ok {
+		if _, ok := f.Lines[line]; ok {
+			f.Lines[line]++
+		}
+	}
+}
+
+// Branch counts arm being taken at the branch point whose first token is
+// at in the file at path.
+func (p *Profile) Branch(path string, at token.Token, arm int) {
+	if f, ok := p.files[path]; ok {
+		if b, ok := f.branches[at.Offset]; ok && arm < len(b.Taken) {
+			b.Taken[arm]++
+		}
+	}
+}
+
+// Files returns the coverage of the registered files, ordered by path.
+func (p *Profile) Files() []*File {
+	return slices.SortedFunc(maps.Values(p.files), func(a, b *File) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+}
+
+// Remove forgets the files for which drop returns true.
+func (p *Profile) Remove(drop func(path string) bool) {
+	maps.DeleteFunc(p.files, func(path string, _ *File) bool { return drop(path) })
+}
+
+// LinesCovered returns how many of the lines holding statements ran.
+func (f *File) LinesCovered() (covered, total int) {
+	for _, hits := range f.Lines {
+		if hits > 0 {
+			covered++
+		}
+	}
+	return covered, len(f.Lines)
+}
+
+// BranchesCovered returns how many branch arms were taken.
+func (f *File) BranchesCovered() (covered, total int) {
+	for _, b := range f.Branches {
+		for _, taken := range b.Taken {
+			if taken > 0 {
+				covered++
+			}
+		}
+		total += len(b.Taken)
+	}
+	return covered, total
+}
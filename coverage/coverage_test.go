@@ -0,0 +1,152 @@
+package coverage_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/coverage"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+const library = `fun check(value) {
+    try {
+        return value + 1;
+    } catch (e) {
+        return nil;
+    }
+}
+
+fun unused() {
+    print "never";
+}
+`
+
+const script = `import "lib.clav";
+lib.check(1);
+lib.check("one");
+try {
+    print lib.check(2);
+} catch (e) {
+    print "unreachable";
+}
+`
+
+// profile runs script, which imports library, and returns its coverage.
+func profile(t *testing.T) (*coverage.Profile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lib.clav"), []byte(library), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "main.clav")
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := scanner.NewScanner(script)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	cover := coverage.New()
+	var out strings.Builder
+	i := interpreter.NewInterpreter(interpreter.WithScriptPath(path), interpreter.WithStdout(&out), interpreter.WithCoverage(cover))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+	return cover, dir
+}
+
+func TestProfile(t *testing.T) {
+	cover, dir := profile(t)
+	files := cover.Files()
+	if len(files) != 2 || files[0].Path != filepath.Join(dir, "lib.clav") || files[1].Path != filepath.Join(dir, "main.clav") {
+		t.Fatalf("files %+v", files)
+	}
+	lib, main := files[0], files[1]
+	want := map[int]int{1: 1, 2: 3, 3: 3, 5: 1, 9: 1, 10: 0}
+	for line, hits := range want {
+		if lib.Lines[line] != hits {
+			t.Errorf("lib.clav line %d ran %d times, want %d", line, lib.Lines[line], hits)
+		}
+	}
+	if len(lib.Lines) != len(want) {
+		t.Errorf("lib.clav lines %v", lib.Lines)
+	}
+	if covered, total := lib.LinesCovered(); covered != 5 || total != 6 {
+		t.Errorf("lib.clav covers %d of %d lines", covered, total)
+	}
+	if len(lib.Branches) != 1 || lib.Branches[0].Line != 2 || lib.Branches[0].Taken[coverage.TryCompleted] != 2 || lib.Branches[0].Taken[coverage.TryCaught] != 1 {
+		t.Errorf("lib.clav branches %+v", lib.Branches[0])
+	}
+	if covered, total := main.BranchesCovered(); covered != 1 || total != 2 {
+		t.Errorf("main.clav covers %d of %d branches", covered, total)
+	}
+
+	cover.Remove(func(path string) bool { return filepath.Base(path) == "main.clav" })
+	if len(cover.Files()) != 1 {
+		t.Errorf("main.clav was not removed")
+	}
+}
+
+func TestReports(t *testing.T) {
+	cover, dir := profile(t)
+
+	var text strings.Builder
+	if err := cover.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"5/6    83.3%  2/2       100.0%", "10/12  83.3%  3/4       75.0%"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("summary lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var lcov strings.Builder
+	if err := cover.WriteLCOV(&lcov); err != nil {
+		t.Fatal(err)
+	}
+	wantLCOV := "TN:\nSF:" + filepath.Join(dir, "lib.clav") + `
+DA:1,1
+DA:2,3
+DA:3,3
+DA:5,1
+DA:9,1
+DA:10,0
+BRDA:2,0,0,2
+BRDA:2,0,1,1
+BRF:2
+BRH:2
+LF:6
+LH:5
+end_of_record
+`
+	if !strings.HasPrefix(lcov.String(), wantLCOV) {
+		t.Errorf("LCOV report:\n%s\nwant it to start with:\n%s", lcov.String(), wantLCOV)
+	}
+	if !strings.Contains(lcov.String(), "BRDA:4,0,0,1\nBRDA:4,0,1,0\n") {
+		t.Errorf("LCOV report lacks the branches of main.clav:\n%s", lcov.String())
+	}
+
+	var html strings.Builder
+	if err := cover.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<tr class="miss"><td class="num">10</td><td class="hits">0</td><td class="src">    print &#34;never&#34;;</td></tr>`,
+		`<tr class="partial" title="branches: completed 1, caught 0"><td class="num">4</td>`,
+		`<tr class=""><td class="num">4</td><td class="hits"></td><td class="src">    } catch (e) {</td></tr>`,
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML report lacks %q", want)
+		}
+	}
+}
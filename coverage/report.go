@@ -0,0 +1,199 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// armNames describes the arms of a try statement's branch.
+func armNames() []string {
+	return []string{"completed", "caught"}
+}
+
+// WriteText writes a table of the line and branch coverage of every file
+// and of all of them together.
+func (p *Profile) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "file\tlines\t\tbranches\t")
+	var lines, lineTotal, branches, branchTotal int
+	for _, f := range p.Files() {
+		l, lt := f.LinesCovered()
+		b, bt := f.BranchesCovered()
+		lines, lineTotal, branches, branchTotal = lines+l, lineTotal+lt, branches+b, branchTotal+bt
+		fmt.Fprintf(tw, "%s\t%d/%d\t%s\t%d/%d\t%s\n", displayPath(f.Path), l, lt, percent(l, lt), b, bt, percent(b, bt))
+	}
+	fmt.Fprintf(tw, "total\t%d/%d\t%s\t%d/%d\t%s\n", lines, lineTotal, percent(lines, lineTotal), branches, branchTotal, percent(branches, branchTotal))
+	return tw.Flush()
+}
+
+func percent(covered, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(100*float64(covered)/float64(total), 'f', 1, 64) + "%"
+}
+
+// WriteLCOV writes the profile in the LCOV tracefile format read by genhtml
+// and most coverage services.
+func (p *Profile) WriteLCOV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range p.Files() {
+		fmt.Fprintln(bw, "TN:")
+		fmt.Fprintf(bw, "SF:%s\n", f.Path)
+		for _, line := range f.lineNumbers() {
+			fmt.Fprintf(bw, "DA:%d,%d\n", line, f.Lines[line])
+		}
+		for block, b := range f.Branches {
+			reached := slices.ContainsFunc(b.Taken, func(n int) bool { return n > 0 })
+			for arm, taken := range b.Taken {
+				count := "-"
+				if reached {
+					count = strconv.Itoa(taken)
+				}
+				fmt.Fprintf(bw, "BRDA:%d,%d,%d,%s\n", b.Line, block, arm, count)
+			}
+		}
+		branches, branchTotal := f.BranchesCovered()
+		fmt.Fprintf(bw, "BRF:%d\nBRH:%d\n", branchTotal, branches)
+		lines, lineTotal := f.LinesCovered()
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\n", lineTotal, lines)
+		fmt.Fprintln(bw, "end_of_record")
+	}
+	return bw.Flush()
+}
+
+func (f *File) lineNumbers() []int {
+	lines := make([]int, 0, len(f.Lines))
+	for line := range f.Lines {
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// WriteHTML writes a page showing the source of every file with the lines
+// that ran, the lines that did not and the branches not fully taken
+// highlighted. Sources are read from disk.
+func (p *Profile) WriteHTML(w io.Writer) error {
+	page := htmlPage{}
+	for _, f := range p.Files() {
+		l, lt := f.LinesCovered()
+		b, bt := f.BranchesCovered()
+		file := htmlFile{
+			Name:     displayPath(f.Path),
+			Lines:    percent(l, lt),
+			Branches: percent(b, bt),
+		}
+		var source []string
+		if f.Path != "" {
+			if data, err := os.ReadFile(f.Path); err == nil {
+				source = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			}
+		}
+		branches := map[int][]*Branch{}
+		for _, b := range f.Branches {
+			branches[b.Line] = append(branches[b.Line], b)
+		}
+		last := len(source)
+		if numbers := f.lineNumbers(); len(numbers) > 0 {
+			last = max(last, numbers[len(numbers)-1])
+		}
+		for n := 1; n <= last; n++ {
+			line := htmlLine{Number: n}
+			if n <= len(source) {
+				line.Text = source[n-1]
+			}
+			if hits, ok := f.Lines[n]; ok {
+				line.Hits = strconv.Itoa(hits)
+				line.Class = "hit"
+				if hits == 0 {
+					line.Class = "miss"
+				}
+			}
+			var notes []string
+			for _, b := range branches[n] {
+				for arm, taken := range b.Taken {
+					notes = append(notes, armNames()[arm]+" "+strconv.Itoa(taken))
+					if taken == 0 && line.Class == "hit" {
+						line.Class = "partial"
+					}
+				}
+			}
+			line.Branches = strings.Join(notes, ", ")
+			file.Source = append(file.Source, line)
+		}
+		page.Files = append(page.Files, file)
+	}
+	return htmlTemplate().Execute(w, page)
+}
+
+type htmlPage struct {
+	Files []htmlFile
+}
+
+type htmlFile struct {
+	Name     string
+	Lines    string
+	Branches string
+	Source   []htmlLine
+}
+
+type htmlLine struct {
+	Number   int
+	Hits     string
+	Class    string
+	Text     string
+	Branches string
+}
+
+func htmlTemplate() *template.Template {
+	return template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>clav coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; }
+td { padding: 0 .5em; white-space: pre; }
+td.num, td.hits { color: #888; text-align: right; }
+tr.hit td.src { background: #dfd; }
+tr.miss td.src { background: #fdd; }
+tr.partial td.src { background: #ffc; }
+</style>
+</head>
+<body>
+{{range .Files}}<h2>{{.Name}}</h2>
+<p>lines {{.Lines}}, branches {{.Branches}}</p>
+<table>
+{{range .Source}}<tr class="{{.Class}}"{{with .Branches}} title="branches: {{.}}"{{end}}><td class="num">{{.Number}}</td><td class="hits">{{.Hits}}</td><td class="src">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+}
+
+// displayPath shortens path relative to the working directory when it lies
+// below it.
+func displayPath(path string) string {
+	if path == "" {
+		return "<script>"
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
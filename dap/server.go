@@ -0,0 +1,546 @@
+// Package dap implements a Debug Adapter Protocol server for clav.
+//
+// A session debugs a single launched program, run on its own goroutine by
+// an Interpreter whose debugger is a debug.Session. While the program is
+// stopped its goroutine waits for the server to say how to continue, and
+// the server inspects the paused Interpreter to answer stack, scope,
+// variable and evaluate requests.
+package dap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/it-a-me/clavlang/debug"
+	"github.com/it-a-me/clavlang/framing"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// threadID identifies the only thread a clav program has.
+const threadID = 1
+
+type Server struct {
+	in      *bufio.Reader
+	options func(program string) []interpreter.Option
+
+	// mu guards out and seq, which events sent by the program share with
+	// the server.
+	mu  sync.Mutex
+	out io.Writer
+	seq int
+
+	program *program
+	// resume is sent to the stopped program once the response to the
+	// request choosing it has been written.
+	resume *debug.Action
+}
+
+// program is a launched program.
+type program struct {
+	args    LaunchArguments
+	path    string
+	stmts   []parser.Stmt
+	lines   map[int]bool
+	session *debug.Session
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	actions chan debug.Action
+
+	// mu guards the fields describing the stopped program. interp is only
+	// set while the program is stopped and handles lists the values that
+	// variable references handed out since then refer to.
+	mu      sync.Mutex
+	interp  *interpreter.Interpreter
+	handles []any
+}
+
+// NewServer returns a Server reading requests from in and writing to out.
+// options, which may be nil, configures the Interpreter for a program.
+func NewServer(in io.Reader, out io.Writer, options func(program string) []interpreter.Option) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, options: options}
+}
+
+// Run answers requests until the client disconnects or closes the input.
+func (s *Server) Run() error {
+	defer s.stop(false)
+	for {
+		data, err := framing.Read(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		body, err := s.handle(req)
+		resp := response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+		if err != nil {
+			resp.Message = err.Error()
+		}
+		if err := s.send(&resp.Seq, &resp); err != nil {
+			return err
+		}
+		switch {
+		case s.resume != nil:
+			s.program.actions <- *s.resume
+			s.resume = nil
+		case req.Command == "launch" && err == nil:
+			// Breakpoints can be verified against the program from now on.
+			if err := s.event("initialized", nil); err != nil {
+				return err
+			}
+		case req.Command == "disconnect":
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(req request) (any, error) {
+	switch req.Command {
+	case "initialize":
+		return Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsConditionalBreakpoints:   true,
+			SupportsEvaluateForHovers:        true,
+			SupportsTerminateRequest:         true,
+		}, nil
+	case "launch":
+		var args LaunchArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return nil, s.launch(args)
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.setBreakpoints(args)
+	case "configurationDone":
+		return nil, s.start()
+	case "threads":
+		return ThreadsResponse{Threads: []Thread{{ID: threadID, Name: "main"}}}, nil
+	case "stackTrace":
+		var args StackTraceArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.stackTrace(args)
+	case "scopes":
+		var args ScopesArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.scopes(args)
+	case "variables":
+		var args VariablesArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.variables(args)
+	case "evaluate":
+		var args EvaluateArguments
+		if err := decode(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.evaluate(args)
+	case "continue":
+		return ContinueResponse{AllThreadsContinued: true}, s.continueWith(debug.Continue)
+	case "next":
+		return nil, s.continueWith(debug.StepOver)
+	case "stepIn":
+		return nil, s.continueWith(debug.StepIn)
+	case "stepOut":
+		return nil, s.continueWith(debug.StepOut)
+	case "terminate":
+		s.stop(true)
+		return nil, nil
+	case "disconnect":
+		s.stop(true)
+		return nil, nil
+	}
+	return nil, errors.New("unsupported request " + strconv.Quote(req.Command))
+}
+
+func decode(arguments json.RawMessage, v any) error {
+	if len(arguments) == 0 {
+		return nil
+	}
+	return json.Unmarshal(arguments, v)
+}
+
+// send writes a response or event, numbering it through seq.
+func (s *Server) send(seq *int, message any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	*seq = s.seq
+	return framing.Write(s.out, message)
+}
+
+func (s *Server) event(name string, body any) error {
+	e := event{Type: "event", Event: name, Body: body}
+	return s.send(&e.Seq, &e)
+}
+
+// launch reads and parses the program. It runs once the client is done
+// setting breakpoints.
+func (s *Server) launch(args LaunchArguments) error {
+	if s.program != nil {
+		return errors.New("a program has already been launched")
+	}
+	if args.Program == "" {
+		return errors.New("launch needs the path of a program")
+	}
+	path, err := filepath.Abs(args.Program)
+	if err != nil {
+		return err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sc := scanner.NewScanner(string(source))
+	tokens, errs := sc.Scan()
+	if errs != nil {
+		return errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		return errors.Join(errs...)
+	}
+	s.program = &program{
+		args:    args,
+		path:    path,
+		stmts:   stmts,
+		lines:   debug.StatementLines(stmts),
+		done:    make(chan struct{}),
+		actions: make(chan debug.Action),
+	}
+	s.program.session = debug.New(path, args.StopOnEntry, s.pause)
+	return nil
+}
+
+func (s *Server) launched() (*program, error) {
+	if s.program == nil {
+		return nil, errors.New("no program has been launched")
+	}
+	return s.program, nil
+}
+
+// setBreakpoints replaces the breakpoints of the launched program. Lines
+// without a statement cannot be stopped at, so their breakpoints are not
+// verified.
+func (s *Server) setBreakpoints(args SetBreakpointsArguments) (SetBreakpointsResponse, error) {
+	p, err := s.launched()
+	if err != nil {
+		return SetBreakpointsResponse{}, err
+	}
+	resp := SetBreakpointsResponse{Breakpoints: []Breakpoint{}}
+	path, err := filepath.Abs(args.Source.Path)
+	if err != nil || path != p.path {
+		for _, bp := range args.Breakpoints {
+			resp.Breakpoints = append(resp.Breakpoints, Breakpoint{Line: bp.Line, Message: "Breakpoints can only be set in the launched program"})
+		}
+		return resp, nil
+	}
+	p.session.ClearBreakpoints()
+	for _, bp := range args.Breakpoints {
+		result := Breakpoint{Line: bp.Line}
+		switch err := p.session.SetBreakpoint(debug.LineBreakpoint{Line: bp.Line, Condition: bp.Condition}); {
+		case err != nil:
+			result.Message = "Invalid condition: " + err.Error()
+		case !p.lines[bp.Line]:
+			result.Message = "No statement on line " + strconv.Itoa(bp.Line)
+		default:
+			result.Verified = true
+		}
+		resp.Breakpoints = append(resp.Breakpoints, result)
+	}
+	return resp, nil
+}
+
+// start runs the launched program on its own goroutine.
+func (s *Server) start() error {
+	p, err := s.launched()
+	if err != nil {
+		return err
+	}
+	if p.started {
+		return nil
+	}
+	p.started = true
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	var opts []interpreter.Option
+	if s.options != nil {
+		opts = s.options(p.path)
+	}
+	opts = append(opts,
+		interpreter.WithScriptPath(p.path),
+		interpreter.WithArgs(p.args.Args),
+		interpreter.WithStdout(output{server: s, category: "stdout"}),
+	)
+	if !p.args.NoDebug {
+		opts = append(opts, interpreter.WithDebugger(p.session))
+	}
+	go func() {
+		defer close(p.done)
+		err := interpreter.NewInterpreter(opts...).InterpretContext(p.ctx, p.stmts)
+		code := 0
+		var exit interpreter.ExitError
+		switch {
+		case err == nil, errors.Is(err, debug.ErrQuit), errors.Is(err, context.Canceled):
+		case errors.As(err, &exit):
+			code = exit.Code
+		default:
+			_ = s.event("output", OutputEvent{Category: "stderr", Output: err.Error() + "\n"})
+			code = 1
+		}
+		_ = s.event("exited", ExitedEvent{ExitCode: code})
+		_ = s.event("terminated", nil)
+	}()
+	return nil
+}
+
+// stop ends the program, waiting for it to finish when wait is set.
+func (s *Server) stop(wait bool) {
+	p := s.program
+	if p == nil || !p.started {
+		return
+	}
+	p.cancel()
+	if wait {
+		<-p.done
+	}
+}
+
+// pause runs on the program's goroutine whenever it stops.
+func (s *Server) pause(i *interpreter.Interpreter, stop debug.Stop) debug.Action {
+	p := s.program
+	p.mu.Lock()
+	p.interp = i
+	p.handles = nil
+	p.mu.Unlock()
+	stopped := StoppedEvent{Reason: string(stop.Reason), ThreadID: threadID, AllThreadsStopped: true}
+	if stop.Err != nil {
+		stopped.Text = "Breakpoint condition failed: " + stop.Err.Error()
+	}
+	if err := s.event("stopped", stopped); err != nil {
+		return debug.Quit
+	}
+	select {
+	case action := <-p.actions:
+		return action
+	case <-p.ctx.Done():
+		return debug.Quit
+	}
+}
+
+// stopped returns the Interpreter of the stopped program.
+func (s *Server) stopped() (*program, *interpreter.Interpreter, error) {
+	p, err := s.launched()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.interp == nil {
+		return nil, nil, errors.New("the program is not stopped")
+	}
+	return p, p.interp, nil
+}
+
+func (s *Server) continueWith(action debug.Action) error {
+	p, _, err := s.stopped()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.interp = nil
+	p.mu.Unlock()
+	s.resume = &action
+	return nil
+}
+
+func (s *Server) stackTrace(args StackTraceArguments) (StackTraceResponse, error) {
+	_, i, err := s.stopped()
+	if err != nil {
+		return StackTraceResponse{}, err
+	}
+	stack := i.Stack()
+	resp := StackTraceResponse{StackFrames: []StackFrame{}, TotalFrames: len(stack)}
+	end := len(stack)
+	if args.Levels > 0 {
+		end = min(end, args.StartFrame+args.Levels)
+	}
+	for index := args.StartFrame; index < end; index++ {
+		frame := stack[index]
+		sf := StackFrame{ID: index + 1, Name: frame.Function, Line: frame.Line, Column: 1}
+		if frame.File != "" {
+			sf.Source = &Source{Name: filepath.Base(frame.File), Path: frame.File}
+		}
+		resp.StackFrames = append(resp.StackFrames, sf)
+	}
+	return resp, nil
+}
+
+// scopes lists the environment chain of a frame, from its innermost scope
+// out to the globals.
+func (s *Server) scopes(args ScopesArguments) (ScopesResponse, error) {
+	p, i, err := s.stopped()
+	if err != nil {
+		return ScopesResponse{}, err
+	}
+	chain := i.Scopes(args.FrameID - 1)
+	if chain == nil {
+		return ScopesResponse{}, errors.New("no frame with id " + strconv.Itoa(args.FrameID))
+	}
+	resp := ScopesResponse{Scopes: []Scope{}}
+	for depth, vars := range chain {
+		name := "Block " + strconv.Itoa(depth)
+		switch depth {
+		case len(chain) - 1:
+			name = "Globals"
+		case 0:
+			name = "Locals"
+		}
+		resp.Scopes = append(resp.Scopes, Scope{Name: name, VariablesReference: p.handle(vars)})
+	}
+	return resp, nil
+}
+
+func (s *Server) variables(args VariablesArguments) (VariablesResponse, error) {
+	p, _, err := s.stopped()
+	if err != nil {
+		return VariablesResponse{}, err
+	}
+	p.mu.Lock()
+	index := args.VariablesReference - 1
+	if index < 0 || index >= len(p.handles) {
+		p.mu.Unlock()
+		return VariablesResponse{}, errors.New("unknown variables reference " + strconv.Itoa(args.VariablesReference))
+	}
+	value := p.handles[index]
+	p.mu.Unlock()
+
+	resp := VariablesResponse{Variables: []Variable{}}
+	add := func(name string, value types.ClavType) {
+		resp.Variables = append(resp.Variables, p.variable(name, value))
+	}
+	switch v := value.(type) {
+	case map[string]types.ClavType:
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			add(name, v[name])
+		}
+	case *types.List:
+		for index, element := range v.Elements {
+			add(strconv.Itoa(index), element)
+		}
+	case *types.Map:
+		for _, key := range v.Keys() {
+			element, _ := v.Get(key)
+			add(key, element)
+		}
+	case types.Module:
+		for _, name := range slices.Sorted(maps.Keys(v.Members)) {
+			add(name, v.Members[name])
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) evaluate(args EvaluateArguments) (EvaluateResponse, error) {
+	p, i, err := s.stopped()
+	if err != nil {
+		return EvaluateResponse{}, err
+	}
+	frame := max(args.FrameID-1, 0)
+	value, err := i.Evaluate(frame, args.Expression)
+	if err != nil {
+		return EvaluateResponse{}, err
+	}
+	v := p.variable("", value)
+	return EvaluateResponse{Result: v.Value, Type: v.Type, VariablesReference: v.VariablesReference}, nil
+}
+
+// variable describes a value, giving values with members of their own a
+// reference to expand them by.
+func (p *program) variable(name string, value types.ClavType) Variable {
+	v := Variable{Name: name, Value: debug.Format(value), Type: typeName(value)}
+	switch value := value.(type) {
+	case *types.List:
+		if len(value.Elements) > 0 {
+			v.VariablesReference = p.handle(value)
+		}
+	case *types.Map:
+		if value.Len() > 0 {
+			v.VariablesReference = p.handle(value)
+		}
+	case types.Module:
+		v.VariablesReference = p.handle(value)
+	}
+	return v
+}
+
+// handle returns a variables reference to value, valid until the program
+// next continues.
+func (p *program) handle(value any) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handles = append(p.handles, value)
+	return len(p.handles)
+}
+
+func typeName(value types.ClavType) string {
+	switch value.(type) {
+	case types.Number:
+		return "Number"
+	case types.String:
+		return "String"
+	case types.Boolean:
+		return "Boolean"
+	case types.Nil, nil:
+		return "Nil"
+	case *types.List:
+		return "List"
+	case *types.Map:
+		return "Map"
+	case types.Module:
+		return "Module"
+	case types.Callable:
+		return "Function"
+	}
+	return ""
+}
+
+// output sends what the program prints to the client as output events.
+type output struct {
+	server   *Server
+	category string
+}
+
+func (o output) Write(data []byte) (int, error) {
+	if err := o.server.event("output", OutputEvent{Category: o.category, Output: string(data)}); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
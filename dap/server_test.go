@@ -0,0 +1,334 @@
+package dap_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/dap"
+)
+
+// client drives a Server running in the same process over a pair of pipes,
+// the way an editor would.
+type client struct {
+	t      *testing.T
+	in     io.WriteCloser
+	out    *bufio.Reader
+	seq    int
+	done   chan error
+	events []message
+}
+
+type message struct {
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		c.done <- dap.NewServer(serverIn, serverOut, nil).Run()
+		serverOut.Close()
+	}()
+	return c
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+	length := 0
+	for {
+		line, err := c.out.ReadString('\n')
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			if length, err = strconv.Atoi(value); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.out, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var m message
+	if err := json.Unmarshal(body, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// request sends a request and returns its response, queueing the events
+// that arrive before it.
+func (c *client) request(command string, arguments any) message {
+	c.t.Helper()
+	c.seq++
+	body, err := json.Marshal(map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		m := c.read()
+		if m.Type == "event" {
+			c.events = append(c.events, m)
+			continue
+		}
+		if m.RequestSeq != c.seq {
+			c.t.Fatalf("response to request %d while waiting for %d", m.RequestSeq, c.seq)
+		}
+		return m
+	}
+}
+
+// call sends a request that must succeed and decodes its body into body.
+func (c *client) call(command string, arguments, body any) {
+	c.t.Helper()
+	m := c.request(command, arguments)
+	if !m.Success {
+		c.t.Fatalf("%s failed: %s", command, m.Message)
+	}
+	if body != nil {
+		if err := json.Unmarshal(m.Body, body); err != nil {
+			c.t.Fatalf("%s: decode %s: %v", command, m.Body, err)
+		}
+	}
+}
+
+// wait returns the next event called name, along with the output printed
+// before it.
+func (c *client) wait(name string) (message, string) {
+	c.t.Helper()
+	var output strings.Builder
+	for {
+		var m message
+		if len(c.events) > 0 {
+			m, c.events = c.events[0], c.events[1:]
+		} else {
+			m = c.read()
+		}
+		if m.Type != "event" {
+			c.t.Fatalf("unexpected %s while waiting for %s", m.Type, name)
+		}
+		if m.Event == name {
+			return m, output.String()
+		}
+		if m.Event == "output" {
+			var body dap.OutputEvent
+			if err := json.Unmarshal(m.Body, &body); err != nil {
+				c.t.Fatal(err)
+			}
+			output.WriteString(body.Output)
+		}
+	}
+}
+
+// stopped waits for the program to stop and returns the reason, the line
+// of the innermost frame and the output printed before.
+func (c *client) stopped() (string, int, string) {
+	c.t.Helper()
+	m, output := c.wait("stopped")
+	var event dap.StoppedEvent
+	if err := json.Unmarshal(m.Body, &event); err != nil {
+		c.t.Fatal(err)
+	}
+	var trace dap.StackTraceResponse
+	c.call("stackTrace", map[string]any{"threadId": 1}, &trace)
+	return event.Reason, trace.StackFrames[0].Line, output
+}
+
+func (c *client) finish() {
+	c.t.Helper()
+	c.call("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) launch(source string, stopOnEntry bool, breakpoints ...map[string]any) string {
+	c.t.Helper()
+	path := filepath.Join(c.t.TempDir(), "main.clav")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		c.t.Fatal(err)
+	}
+	var capabilities dap.Capabilities
+	c.call("initialize", map[string]any{"adapterID": "clav"}, &capabilities)
+	if !capabilities.SupportsConditionalBreakpoints || !capabilities.SupportsConfigurationDoneRequest {
+		c.t.Errorf("capabilities %+v", capabilities)
+	}
+	c.call("launch", map[string]any{"program": path, "stopOnEntry": stopOnEntry}, nil)
+	c.wait("initialized")
+	if breakpoints != nil {
+		var set dap.SetBreakpointsResponse
+		c.call("setBreakpoints", map[string]any{"source": map[string]any{"path": path}, "breakpoints": breakpoints}, &set)
+		for j, bp := range set.Breakpoints {
+			if want := j < len(breakpoints)-1; bp.Verified != want {
+				c.t.Errorf("breakpoint %d verified is %v: %s", j, bp.Verified, bp.Message)
+			}
+		}
+	}
+	c.call("configurationDone", nil, nil)
+	return path
+}
+
+const program = `var items = [1, "two"];
+fun double(n) {
+  var result = n * 2;
+  return result;
+}
+print double(4);
+print double(5);
+print "done";
+`
+
+func TestBreakpoints(t *testing.T) {
+	c := newClient(t)
+	// The last breakpoint is on a line without a statement and is never
+	// verified.
+	path := c.launch(program, false,
+		map[string]any{"line": 3, "condition": "n == 5"},
+		map[string]any{"line": 5},
+	)
+
+	m, output := c.wait("stopped")
+	if output != "8\n" {
+		t.Errorf("output before the breakpoint %q", output)
+	}
+	if !strings.Contains(string(m.Body), `"reason":"breakpoint"`) {
+		t.Errorf("stopped %s", m.Body)
+	}
+
+	var threads dap.ThreadsResponse
+	c.call("threads", nil, &threads)
+	if len(threads.Threads) != 1 {
+		t.Errorf("threads %+v", threads)
+	}
+
+	var trace dap.StackTraceResponse
+	c.call("stackTrace", map[string]any{"threadId": 1}, &trace)
+	if len(trace.StackFrames) != 2 || trace.TotalFrames != 2 {
+		t.Fatalf("stack %+v", trace)
+	}
+	inner, outer := trace.StackFrames[0], trace.StackFrames[1]
+	if inner.Name != "double" || inner.Line != 3 || inner.Source == nil || inner.Source.Path != path {
+		t.Errorf("innermost frame %+v", inner)
+	}
+	if outer.Name != "<script>" || outer.Line != 7 {
+		t.Errorf("outermost frame %+v", outer)
+	}
+
+	var scopes dap.ScopesResponse
+	c.call("scopes", map[string]any{"frameId": inner.ID}, &scopes)
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "Locals" || scopes.Scopes[1].Name != "Globals" {
+		t.Fatalf("scopes %+v", scopes)
+	}
+	variables := func(reference int) string {
+		t.Helper()
+		var vars dap.VariablesResponse
+		c.call("variables", map[string]any{"variablesReference": reference}, &vars)
+		var parts []string
+		for _, v := range vars.Variables {
+			parts = append(parts, v.Name+"="+v.Value)
+		}
+		return strings.Join(parts, " ")
+	}
+	if got := variables(scopes.Scopes[0].VariablesReference); got != "n=5" {
+		t.Errorf("locals %s", got)
+	}
+	var globals dap.VariablesResponse
+	c.call("variables", map[string]any{"variablesReference": scopes.Scopes[1].VariablesReference}, &globals)
+	if len(globals.Variables) != 2 || globals.Variables[1].Name != "items" || globals.Variables[1].Type != "List" {
+		t.Fatalf("globals %+v", globals)
+	}
+	if got := variables(globals.Variables[1].VariablesReference); got != `0=1 1="two"` {
+		t.Errorf("items %s", got)
+	}
+
+	var result dap.EvaluateResponse
+	c.call("evaluate", map[string]any{"expression": "n * 10", "frameId": inner.ID}, &result)
+	if result.Result != "50" || result.Type != "Number" {
+		t.Errorf("evaluate %+v", result)
+	}
+	if m := c.request("evaluate", map[string]any{"expression": "n", "frameId": outer.ID}); m.Success || !strings.Contains(m.Message, "Undefined variable 'n'") {
+		t.Errorf("n is not visible in the outer frame, got %+v", m)
+	}
+
+	c.call("next", map[string]any{"threadId": 1}, nil)
+	if reason, line, _ := c.stopped(); reason != "step" || line != 4 {
+		t.Errorf("next stopped on line %d (%s)", line, reason)
+	}
+	c.call("stepOut", map[string]any{"threadId": 1}, nil)
+	if reason, line, output := c.stopped(); reason != "step" || line != 8 || output != "10\n" {
+		t.Errorf("stepOut stopped on line %d (%s) after %q", line, reason, output)
+	}
+	c.call("continue", map[string]any{"threadId": 1}, nil)
+	m, output = c.wait("exited")
+	if output != "done\n" || !strings.Contains(string(m.Body), `"exitCode":0`) {
+		t.Errorf("exited %s after %q", m.Body, output)
+	}
+	c.wait("terminated")
+	c.finish()
+}
+
+func TestStepping(t *testing.T) {
+	c := newClient(t)
+	c.launch(program, true)
+	reason, line, _ := c.stopped()
+	lines := []int{line}
+	if reason != "entry" {
+		t.Errorf("first stopped for %s", reason)
+	}
+	for range 3 {
+		c.call("stepIn", map[string]any{"threadId": 1}, nil)
+		reason, line, _ := c.stopped()
+		lines = append(lines, line)
+		if reason != "step" {
+			t.Errorf("stopped for %s", reason)
+		}
+	}
+	if fmt.Sprint(lines) != "[1 2 6 3]" {
+		t.Errorf("stepped through lines %v", lines)
+	}
+	var result dap.EvaluateResponse
+	c.call("evaluate", map[string]any{"expression": "n"}, &result)
+	if result.Result != "4" {
+		t.Errorf("n is %s inside the first call", result.Result)
+	}
+	// Disconnecting stops the paused program.
+	c.finish()
+}
+
+func TestRuntimeError(t *testing.T) {
+	c := newClient(t)
+	c.launch("print 1;\nprint 1 + \"x\";\n", false)
+	m, output := c.wait("exited")
+	if output != "1\nError on line 2: Cannot add values of different types\n" {
+		t.Errorf("output %q", output)
+	}
+	if !strings.Contains(string(m.Body), `"exitCode":1`) {
+		t.Errorf("exited %s", m.Body)
+	}
+	if m := c.request("evaluate", map[string]any{"expression": "1"}); m.Success {
+		t.Error("evaluate succeeded without a stopped program")
+	}
+	c.finish()
+}
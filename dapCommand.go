@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/it-a-me/clavlang/dap"
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func dapCommand(args []string) {
+	flags := flag.NewFlagSet("dap", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: clav dap [flags]\n\nServes the Debug Adapter Protocol over standard input and output. The flags\napply to every program launched.")
+		flags.PrintDefaults()
+	}
+	interpreterOptions := interpreterFlags(flags)
+	_ = flags.Parse(args)
+	options := func(program string) []interpreter.Option {
+		return append(interpreterOptions(), packageOptions(program)...)
+	}
+	if err := dap.NewServer(os.Stdin, os.Stdout, options).Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+const consoleHelp = `commands:
+  break LINE [if EXPR]  stop at LINE, or only when EXPR is true (b)
+  clear LINE            remove the breakpoint at LINE
+  breakpoints           list breakpoints
+  continue              run to the next breakpoint (c)
+  step                  run to the next statement, entering calls (s)
+  next                  run to the next statement in this function (n)
+  out                   run until this function returns (o)
+  print EXPR            evaluate EXPR in the current frame (p)
+  env                   show the variables of each scope (e)
+  stack                 show the calls in progress (bt)
+  quit                  stop the program (q)`
+
+// Console drives a Session with commands read line by line, as typed at a
+// terminal. Its Session stops before the first statement.
+type Console struct {
+	*Session
+	in  *bufio.Scanner
+	out io.Writer
+	// sources caches the lines of the files the program stops in.
+	sources map[string][]string
+}
+
+// NewConsole returns a Console debugging the program in file, whose
+// contents are source.
+func NewConsole(in io.Reader, out io.Writer, file, source string) *Console {
+	c := &Console{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		sources: map[string][]string{file: strings.Split(source, "\n")},
+	}
+	c.Session = New(file, true, c.pause)
+	return c
+}
+
+func (c *Console) pause(i *interpreter.Interpreter, stop Stop) Action {
+	c.where(stop)
+	for {
+		fmt.Fprint(c.out, "(clav) ")
+		if !c.in.Scan() {
+			// The input is gone, so nobody is left to resume the program.
+			fmt.Fprintln(c.out)
+			return Quit
+		}
+		command, arg, _ := strings.Cut(strings.TrimSpace(c.in.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "":
+		case "c", "continue":
+			return Continue
+		case "s", "step":
+			return StepIn
+		case "n", "next":
+			return StepOver
+		case "o", "out":
+			return StepOut
+		case "q", "quit":
+			return Quit
+		case "b", "break":
+			c.setBreakpoint(arg)
+		case "clear":
+			c.clearBreakpoint(arg)
+		case "breakpoints":
+			for _, bp := range c.Breakpoints() {
+				c.printBreakpoint(bp)
+			}
+		case "p", "print":
+			value, err := i.Evaluate(0, arg)
+			if err != nil {
+				fmt.Fprintln(c.out, err)
+				continue
+			}
+			fmt.Fprintln(c.out, Format(value))
+		case "e", "env":
+			c.env(i)
+		case "bt", "stack":
+			for _, frame := range i.Stack() {
+				fmt.Fprintf(c.out, "  %s at %s:%d\n", frame.Function, displayName(frame.File), frame.Line)
+			}
+		case "h", "help":
+			fmt.Fprintln(c.out, consoleHelp)
+		default:
+			fmt.Fprintf(c.out, "unknown command %q, try help\n", command)
+		}
+	}
+}
+
+// where shows why the program stopped and the line it stopped on.
+func (c *Console) where(stop Stop) {
+	fmt.Fprintf(c.out, "stopped at %s:%d in %s (%s)\n", displayName(stop.Frame.File), stop.Frame.Line, stop.Frame.Function, stop.Reason)
+	if stop.Err != nil {
+		fmt.Fprintln(c.out, "condition failed:", stop.Err)
+	}
+	if line, ok := c.line(stop.Frame.File, stop.Frame.Line); ok {
+		fmt.Fprintf(c.out, "%5d | %s\n", stop.Frame.Line, line)
+	}
+}
+
+func (c *Console) line(file string, line int) (string, bool) {
+	lines, ok := c.sources[file]
+	if !ok {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		c.sources[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return lines[line-1], true
+}
+
+func (c *Console) setBreakpoint(arg string) {
+	lineArg, condition, _ := strings.Cut(arg, " ")
+	condition = strings.TrimSpace(condition)
+	if condition != "" {
+		rest, ok := strings.CutPrefix(condition, "if ")
+		if !ok {
+			fmt.Fprintln(c.out, "usage: break LINE [if EXPR]")
+			return
+		}
+		condition = strings.TrimSpace(rest)
+	}
+	line, err := strconv.Atoi(lineArg)
+	if err != nil || line < 1 {
+		fmt.Fprintln(c.out, "usage: break LINE [if EXPR]")
+		return
+	}
+	bp := LineBreakpoint{Line: line, Condition: condition}
+	if err := c.SetBreakpoint(bp); err != nil {
+		fmt.Fprintln(c.out, "invalid condition:", err)
+		return
+	}
+	c.printBreakpoint(bp)
+}
+
+func (c *Console) clearBreakpoint(arg string) {
+	line, err := strconv.Atoi(arg)
+	if err != nil {
+		fmt.Fprintln(c.out, "usage: clear LINE")
+		return
+	}
+	if !c.ClearBreakpoint(line) {
+		fmt.Fprintf(c.out, "no breakpoint at line %d\n", line)
+	}
+}
+
+func (c *Console) printBreakpoint(bp LineBreakpoint) {
+	if bp.Condition == "" {
+		fmt.Fprintf(c.out, "breakpoint at line %d\n", bp.Line)
+	} else {
+		fmt.Fprintf(c.out, "breakpoint at line %d if %s\n", bp.Line, bp.Condition)
+	}
+}
+
+// env prints the environment chain of the innermost frame, from its own
+// scope out to the globals.
+func (c *Console) env(i *interpreter.Interpreter) {
+	scopes := i.Scopes(0)
+	for depth, scope := range scopes {
+		if depth == len(scopes)-1 {
+			fmt.Fprintln(c.out, "globals:")
+		} else {
+			fmt.Fprintf(c.out, "scope %d:\n", depth)
+		}
+		for _, name := range slices.Sorted(maps.Keys(scope)) {
+			fmt.Fprintf(c.out, "  %s = %s\n", name, Format(scope[name]))
+		}
+	}
+}
+
+func displayName(file string) string {
+	if file == "" {
+		return "<script>"
+	}
+	return filepath.Base(file)
+}
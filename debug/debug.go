@@ -0,0 +1,246 @@
+// Package debug implements breakpoints and stepping on top of the
+// interpreter's debugger hook, together with a line based console to drive
+// them.
+package debug
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// ErrQuit stops a program whose frontend chose Quit.
+var ErrQuit = errors.New("debugger quit")
+
+// Action tells a paused program how to continue.
+type Action int
+
+const (
+	// Continue runs until the next breakpoint.
+	Continue Action = iota
+	// StepIn stops at the next statement, inside any function it calls.
+	StepIn
+	// StepOver stops at the next statement of the current function or one
+	// of its callers.
+	StepOver
+	// StepOut stops at the next statement after the current function
+	// returns.
+	StepOut
+	// Quit stops the program with ErrQuit.
+	Quit
+)
+
+// Reason is why a program stopped.
+type Reason string
+
+const (
+	Entry      Reason = "entry"
+	Breakpoint Reason = "breakpoint"
+	Step       Reason = "step"
+)
+
+// Stop describes a program that paused before running a statement.
+type Stop struct {
+	Reason Reason
+	Frame  interpreter.Frame
+	Stmt   parser.Stmt
+	// Err is set when the condition of the breakpoint could not be
+	// evaluated. The program stops as though it were true.
+	Err error
+}
+
+// LineBreakpoint stops the program before statements on Line, if its
+// Condition, a clav expression, is empty or evaluates to true.
+type LineBreakpoint struct {
+	Line      int
+	Condition string
+}
+
+// PauseFunc is called with the program stopped and returns how it should
+// continue. It may inspect the program through the Interpreter.
+type PauseFunc func(i *interpreter.Interpreter, stop Stop) Action
+
+// Session is an interpreter.Debugger that stops at breakpoints in a single
+// file and after steps. Breakpoints may be changed while the program runs.
+type Session struct {
+	file  string
+	pause PauseFunc
+
+	mu          sync.Mutex
+	breakpoints map[int]LineBreakpoint
+
+	entry  bool
+	action Action
+	// depth is the stack depth the last action was chosen at.
+	depth int
+	// last is where the previous statement ran, so that a breakpoint is
+	// hit once however many statements its line holds. Each call of a
+	// function is a new frame, so a breakpoint in it is hit again.
+	last position
+}
+
+type position struct {
+	file  string
+	line  int
+	frame uint64
+}
+
+// New returns a Session for the program in file, which is compared with
+// interpreter.Frame.File. With stopOnEntry the program stops before its
+// first statement.
+func New(file string, stopOnEntry bool, pause PauseFunc) *Session {
+	s := &Session{file: file, breakpoints: map[int]LineBreakpoint{}, pause: pause}
+	if stopOnEntry {
+		s.entry = true
+		s.action = StepIn
+	}
+	return s
+}
+
+// SetBreakpoint adds a breakpoint, replacing any other on its line.
+func (s *Session) SetBreakpoint(bp LineBreakpoint) error {
+	if bp.Condition != "" {
+		if _, err := interpreter.ParseExpression(bp.Condition); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakpoints[bp.Line] = bp
+	return nil
+}
+
+// ClearBreakpoint removes the breakpoint on line and reports whether there
+// was one.
+func (s *Session) ClearBreakpoint(line int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.breakpoints[line]
+	delete(s.breakpoints, line)
+	return ok
+}
+
+// ClearBreakpoints removes every breakpoint.
+func (s *Session) ClearBreakpoints() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.breakpoints)
+}
+
+// Breakpoints returns the breakpoints ordered by line.
+func (s *Session) Breakpoints() []LineBreakpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.SortedFunc(maps.Values(s.breakpoints), func(a, b LineBreakpoint) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+}
+
+// Statement implements interpreter.Debugger.
+func (s *Session) Statement(i *interpreter.Interpreter, stmt parser.Stmt) error {
+	stack := i.Stack()
+	here := position{file: stack[0].File, line: stack[0].Line, frame: i.FrameID()}
+	stop := Stop{Frame: stack[0], Stmt: stmt}
+	switch {
+	case s.stepDone(len(stack)):
+		stop.Reason = Step
+		if s.entry {
+			stop.Reason = Entry
+		}
+	case here != s.last && s.hit(i, here, &stop):
+		stop.Reason = Breakpoint
+	default:
+		s.last = here
+		return nil
+	}
+	s.last = here
+	s.entry = false
+	s.action = s.pause(i, stop)
+	s.depth = len(stack)
+	if s.action == Quit {
+		return ErrQuit
+	}
+	return nil
+}
+
+func (s *Session) stepDone(depth int) bool {
+	switch s.action {
+	case StepIn:
+		return true
+	case StepOver:
+		return depth <= s.depth
+	case StepOut:
+		return depth < s.depth
+	case Continue, Quit:
+	}
+	return false
+}
+
+// hit reports whether a breakpoint stops the program at here, recording a
+// condition that fails to evaluate in stop.
+func (s *Session) hit(i *interpreter.Interpreter, here position, stop *Stop) bool {
+	s.mu.Lock()
+	bp, ok := s.breakpoints[here.line]
+	s.mu.Unlock()
+	if !ok || (s.file != "" && here.file != s.file) {
+		return false
+	}
+	if bp.Condition == "" {
+		return true
+	}
+	value, err := i.Evaluate(0, bp.Condition)
+	if err != nil {
+		stop.Err = err
+		return true
+	}
+	b, ok := value.(types.Boolean)
+	if !ok {
+		stop.Err = errors.New("breakpoint condition is " + Format(value) + ", not a Boolean")
+		return true
+	}
+	return b.Value
+}
+
+// StatementLines returns the lines on which statements that a Session can
+// stop at begin.
+func StatementLines(stmts []parser.Stmt) map[int]bool {
+	lines := map[int]bool{}
+	var walk func(stmts []parser.Stmt)
+	walk = func(stmts []parser.Stmt) {
+		for _, stmt := range stmts {
+			switch s := stmt.(type) {
+			case parser.Block:
+				walk(s.Statements)
+				continue
+			case parser.Try:
+				walk(s.Body)
+				walk(s.Handler)
+			case parser.Function:
+				walk(s.Body)
+			case parser.Expression, parser.Print, parser.Var, parser.Return, parser.Import:
+			}
+			lines[parser.StmtToken(stmt).Line] = true
+		}
+	}
+	walk(stmts)
+	return lines
+}
+
+// Format renders a value for display, quoting strings so they stand out
+// from other values.
+func Format(value types.ClavType) string {
+	if s, ok := value.(types.String); ok {
+		return strconv.Quote(s.Value)
+	}
+	if value == nil {
+		return types.Nil{}.String()
+	}
+	return value.String()
+}
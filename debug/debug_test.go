@@ -0,0 +1,183 @@
+package debug_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/debug"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+const program = `var total = 0;
+fun add(n) {
+  var next = total + n;
+  total = next;
+  return next;
+}
+add(1);
+add(2);
+print total;
+`
+
+// session runs program under a Console fed commands and returns everything
+// the console and program printed.
+func session(t *testing.T, commands ...string) (string, error) {
+	t.Helper()
+	s := scanner.NewScanner(program)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	var out strings.Builder
+	in := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	console := debug.NewConsole(in, &out, "", program)
+	err := interpreter.NewInterpreter(interpreter.WithStdout(&out), interpreter.WithDebugger(console)).Interpret(stmts)
+	return out.String(), err
+}
+
+// stops lists the lines the program stopped on, as "line reason".
+func stops(transcript string) []string {
+	var lines []string
+	for _, line := range strings.Split(transcript, "\n") {
+		if _, rest, ok := strings.Cut(line, "stopped at <script>:"); ok {
+			number, _, _ := strings.Cut(rest, " ")
+			lines = append(lines, number+" "+rest[strings.LastIndex(rest, "(")+1:len(rest)-1])
+		}
+	}
+	return lines
+}
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     []string
+	}{
+		{"continue", []string{"c"}, []string{"1 entry"}},
+		{"breakpoint", []string{"b 4", "c", "c", "c"}, []string{"1 entry", "4 breakpoint", "4 breakpoint"}},
+		{"conditional", []string{"break 3 if n > 1", "c", "c"}, []string{"1 entry", "3 breakpoint"}},
+		{"clear", []string{"b 3", "c", "clear 3", "c"}, []string{"1 entry", "3 breakpoint"}},
+		{"next", []string{"n", "n", "n", "n", "n"}, []string{"1 entry", "2 step", "7 step", "8 step", "9 step"}},
+		{"step", []string{"n", "n", "s", "s", "s", "s", "c"}, []string{"1 entry", "2 step", "7 step", "3 step", "4 step", "5 step", "8 step"}},
+		{"out", []string{"b 3", "c", "o", "c", "c"}, []string{"1 entry", "3 breakpoint", "8 step", "3 breakpoint"}},
+		{"next over a breakpoint", []string{"b 4", "n", "n", "n", "c", "c"}, []string{"1 entry", "2 step", "7 step", "4 breakpoint", "4 breakpoint"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := session(t, tt.commands...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stops(out); strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("stopped at %q, want %q\n%s", got, tt.want, out)
+			}
+			if !strings.HasSuffix(out, "3\n") {
+				t.Errorf("program did not finish:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestInspection(t *testing.T) {
+	out, err := session(t, "b 3", "c", "p n * 10", "p total = 5", "env", "bt", "p missing", "c", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"(clav) 10\n",
+		"(clav) 5\n",
+		"scope 0:\n  n = 1\nglobals:\n  add = <fn add>\n  total = 5\n",
+		"  add at <script>:3\n  <script> at <script>:7\n",
+		"Undefined variable 'missing'",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	// The assignment made while paused sticks: 5 + 1 + 2.
+	if !strings.HasSuffix(out, "8\n") {
+		t.Errorf("expected the program to print 8:\n%s", out)
+	}
+}
+
+func TestConditionErrors(t *testing.T) {
+	out, err := session(t, "b 3 if n +", "b 3 if n", "c", "c", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "invalid condition:") {
+		t.Errorf("unparsable condition accepted:\n%s", out)
+	}
+	if !strings.Contains(out, "condition failed: breakpoint condition is 1, not a Boolean") {
+		t.Errorf("non-Boolean condition not reported:\n%s", out)
+	}
+}
+
+func TestQuit(t *testing.T) {
+	out, err := session(t, "n", "q")
+	if !errors.Is(err, debug.ErrQuit) {
+		t.Fatalf("expected ErrQuit, got %v", err)
+	}
+	if strings.Contains(out, "3\n") {
+		t.Errorf("program kept running after quit:\n%s", out)
+	}
+	// Running out of commands quits too.
+	if _, err := session(t, "b 4"); !errors.Is(err, debug.ErrQuit) {
+		t.Fatalf("expected ErrQuit at the end of input, got %v", err)
+	}
+}
+
+func TestBreakpointInEachCall(t *testing.T) {
+	const source = `fun f(n) {
+  return n;
+}
+print f(1) + f(2);
+var re = regex.compile("[a-z]");
+print re.replace("ab", f);
+var a = 1; var b = 2;
+`
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	var hits []string
+	session := debug.New("", false, func(i *interpreter.Interpreter, stop debug.Stop) debug.Action {
+		if stop.Frame.Function != "f" {
+			hits = append(hits, stop.Frame.Function)
+			return debug.Continue
+		}
+		n, err := i.Evaluate(0, "n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits = append(hits, debug.Format(n))
+		return debug.Continue
+	})
+	for _, line := range []int{2, 7} {
+		if err := session.SetBreakpoint(debug.LineBreakpoint{Line: line}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out strings.Builder
+	if err := interpreter.NewInterpreter(interpreter.WithStdout(&out), interpreter.WithDebugger(session)).Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+	// Every call stops, but the line holding two statements only once.
+	if got, want := strings.Join(hits, " "), `1 2 "a" "b" <script>`; got != want {
+		t.Errorf("stopped with %s, want %s", got, want)
+	}
+}
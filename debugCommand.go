@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/it-a-me/clavlang/debug"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+func debugCommand(args []string) {
+	flags := flag.NewFlagSet("debug", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: clav debug [flags] file.clav [args...]\n\nRuns the script under an interactive debugger. Type help at the prompt for its commands.")
+		flags.PrintDefaults()
+	}
+	interpreterOptions := interpreterFlags(flags)
+	_ = flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path := flags.Arg(0)
+	source, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := scanner.NewScanner(string(source))
+	tokens, errs := s.Scan()
+	if errs != nil {
+		for _, err := range errs {
+			log.Print(err)
+		}
+		os.Exit(1)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		for _, err := range errs {
+			log.Print(err)
+		}
+		os.Exit(1)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	console := debug.NewConsole(os.Stdin, os.Stdout, abs, string(source))
+	opts := append(interpreterOptions(), packageOptions(path)...)
+	opts = append(opts,
+		interpreter.WithScriptPath(path),
+		interpreter.WithArgs(flags.Args()[1:]),
+		interpreter.WithDebugger(console),
+	)
+	err = interpreter.NewInterpreter(opts...).Interpret(stmts)
+	var exit interpreter.ExitError
+	switch {
+	case err == nil, errors.Is(err, debug.ErrQuit):
+	case errors.As(err, &exit):
+		os.Exit(exit.Code)
+	default:
+		log.Fatal(err)
+	}
+}
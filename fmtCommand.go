@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/it-a-me/clavlang/format"
+)
+
+const fmtUsage = `usage: clav fmt [--check] [--diff] [paths...]
+
+Formats .clav files in place, searching directories recursively. Without
+paths the source is read from standard input and written to standard output.
+`
+
+func fmtCommand(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), fmtUsage)
+		flags.PrintDefaults()
+	}
+	check := flags.Bool("check", false, "list unformatted files and exit with status 1 instead of rewriting them")
+	diff := flags.Bool("diff", false, "print the changes as a diff instead of rewriting files")
+	_ = flags.Parse(args)
+
+	f := formatter{check: *check, diff: *diff}
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		f.source("<stdin>", src, func(out []byte) error {
+			_, err := os.Stdout.Write(out)
+			return err
+		})
+	}
+	for _, path := range flags.Args() {
+		if err := f.walk(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if f.failed || (f.check && f.unformatted) {
+		os.Exit(1)
+	}
+}
+
+type formatter struct {
+	check, diff bool
+
+	failed      bool
+	unformatted bool
+}
+
+// walk formats root or, for a directory, every .clav file below it.
+func (f *formatter) walk(root string) error {
+	return walkSources(root, func(path string) error {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f.source(path, src, func(out []byte) error {
+			if bytes.Equal(src, out) {
+				return nil
+			}
+			// The file exists, so its permissions are kept.
+			return os.WriteFile(path, out, 0o644)
+		})
+		return nil
+	})
+}
+
+// source formats src and reports or writes the result depending on the
+// mode. Files that fail to parse are reported and left alone.
+func (f *formatter) source(name string, src []byte, write func([]byte) error) {
+	out, err := format.Source(src)
+	if err != nil {
+		log.Printf("%s: %v", name, err)
+		f.failed = true
+		return
+	}
+	changed := !bytes.Equal(src, out)
+	f.unformatted = f.unformatted || changed
+	if f.check && changed {
+		fmt.Println(name)
+	}
+	if f.diff {
+		fmt.Print(format.Diff(name, src, out))
+	}
+	if !f.check && !f.diff {
+		if err := write(out); err != nil {
+			log.Printf("%s: %v", name, err)
+			f.failed = true
+		}
+	}
+}
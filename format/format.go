@@ -0,0 +1,39 @@
+// Package format prints clav source in its canonical style.
+//
+// The printer walks the syntax tree and, for every piece of syntax it
+// prints, takes the matching token from the scanned token stream. Token
+// lexemes are printed as written and the comments and blank lines the
+// scanner attached to each token as trivia are kept, so formatting only
+// ever changes whitespace.
+package format
+
+import (
+	"errors"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+// Indent is the indentation of each nested block.
+const Indent = "    "
+
+// Source formats a complete clav program. Source that does not scan or
+// parse is returned as an error rather than formatted.
+func Source(src []byte) ([]byte, error) {
+	s := scanner.NewScanner(string(src))
+	tokens, errs := s.Scan()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	pr := newPrinter(tokens)
+	pr.file(stmts)
+	if pr.err != nil {
+		return nil, pr.err
+	}
+	return []byte(pr.out.String()), nil
+}
@@ -0,0 +1,85 @@
+package format_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/format"
+)
+
+// TestGolden formats each testdata/*.input file and compares the result
+// with the matching .golden file, which must itself be formatted already.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden tests found")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden, err := os.ReadFile(strings.TrimSuffix(input, ".input") + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := format.Source(src)
+			if err != nil {
+				t.Fatalf("format %s: %v", input, err)
+			}
+			if diff := format.Diff(name, golden, got); diff != "" {
+				t.Errorf("formatting %s differs from golden file:\n%s", input, diff)
+			}
+			again, err := format.Source(golden)
+			if err != nil {
+				t.Fatalf("format golden file: %v", err)
+			}
+			if diff := format.Diff(name, golden, again); diff != "" {
+				t.Errorf("formatting is not idempotent:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	for _, src := range []string{`print "unterminated;`, `print 1 +;`, `1 = 2;`} {
+		if out, err := format.Source([]byte(src)); err == nil {
+			t.Errorf("format %q: expected an error, got %q", src, out)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if diff := format.Diff("same", []byte("a\n"), []byte("a\n")); diff != "" {
+		t.Errorf("diff of equal input: %q", diff)
+	}
+	before := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	after := []byte("1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n11\n")
+	want := `--- f.clav
++++ f.clav
+@@ -1,7 +1,7 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+@@ -8,3 +8,4 @@
+ 8
+ 9
+ 10
++11
+`
+	if diff := format.Diff("f.clav", before, after); diff != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", diff, want)
+	}
+}
@@ -0,0 +1,312 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/token"
+)
+
+type printer struct {
+	tokens []token.Token
+	next   int
+	err    error
+
+	out    strings.Builder
+	indent int
+	// lineStart reports that nothing was written on the current line yet.
+	lineStart bool
+	// broken is set after a line comment in the middle of a statement; the
+	// next write continues the statement on a new, further indented line.
+	broken    bool
+	continued bool
+	spaced    bool
+	// blank requests an empty line before the next line written. It is
+	// never set at the top of a file or block, where blockStart is true.
+	blank      bool
+	blockStart bool
+}
+
+func newPrinter(tokens []token.Token) *printer {
+	return &printer{tokens: tokens, lineStart: true, blockStart: true}
+}
+
+func (p *printer) file(stmts []parser.Stmt) {
+	p.statements(stmts)
+	// Comments after the last statement belong to the EOF token.
+	p.leading(p.take(token.EOF))
+}
+
+func (p *printer) statements(stmts []parser.Stmt) {
+	for _, stmt := range stmts {
+		p.statement(stmt)
+		p.newline()
+		p.blockStart = false
+	}
+}
+
+func (p *printer) statement(stmt parser.Stmt) {
+	switch s := stmt.(type) {
+	case parser.Print:
+		p.token(token.Print)
+		p.space()
+		p.expr(s.Inner)
+		p.token(token.Semicolon)
+	case parser.Expression:
+		p.expr(s.Inner)
+		p.token(token.Semicolon)
+	case parser.Var:
+		p.token(token.Var)
+		p.space()
+		p.token(token.Identifier)
+		if s.Initializer != nil {
+			p.space()
+			p.token(token.Equal)
+			p.space()
+			p.expr(s.Initializer)
+		}
+		p.token(token.Semicolon)
+	case parser.Block:
+		p.block(s.Statements)
+	case parser.Function:
+		p.token(token.Fun)
+		p.space()
+		p.token(token.Identifier)
+		p.token(token.LeftParen)
+		p.list(len(s.Params), func(int) { p.token(token.Identifier) })
+		p.token(token.RightParen)
+		p.space()
+		p.block(s.Body)
+	case parser.Try:
+		p.token(token.Try)
+		p.space()
+		p.block(s.Body)
+		p.space()
+		p.token(token.Catch)
+		p.space()
+		p.token(token.LeftParen)
+		p.token(token.Identifier)
+		p.token(token.RightParen)
+		p.space()
+		p.block(s.Handler)
+	case parser.Return:
+		p.token(token.Return)
+		if s.Value != nil {
+			p.space()
+			p.expr(s.Value)
+		}
+		p.token(token.Semicolon)
+	case parser.Import:
+		p.importStatement(s)
+	}
+}
+
+func (p *printer) importStatement(s parser.Import) {
+	if s.Keyword.Type == token.Import {
+		p.token(token.Import)
+		p.space()
+		p.token(token.String)
+		if s.Alias.Lexeme != "" {
+			p.space()
+			p.token(token.As)
+			p.space()
+			p.token(token.Identifier)
+		}
+	} else {
+		p.token(token.From)
+		p.space()
+		p.token(token.String)
+		p.space()
+		p.token(token.Import)
+		p.space()
+		p.list(len(s.Names), func(int) { p.token(token.Identifier) })
+	}
+	p.token(token.Semicolon)
+}
+
+// block prints a braced list of statements, keeping empty blocks on one
+// line unless they hold comments.
+func (p *printer) block(stmts []parser.Stmt) {
+	open := p.token(token.LeftBrace)
+	if len(stmts) == 0 && len(open.Trailing) == 0 && p.peek().Type == token.RightBrace && !hasComments(p.peek()) {
+		p.token(token.RightBrace)
+		return
+	}
+	p.newline()
+	p.indent++
+	p.blockStart = true
+	p.statements(stmts)
+	// Comments before the closing brace are indented like the statements.
+	closing := p.take(token.RightBrace)
+	p.leading(closing)
+	p.blank = false
+	p.indent--
+	p.write(closing.Lexeme)
+	p.trailing(closing)
+}
+
+func (p *printer) expr(expr parser.Expr) {
+	switch e := expr.(type) {
+	case parser.Binary:
+		p.expr(e.Left)
+		p.space()
+		p.token(e.Operator.Type)
+		p.space()
+		p.expr(e.Right)
+	case parser.Grouping:
+		p.token(token.LeftParen)
+		p.expr(e.Expression)
+		p.token(token.RightParen)
+	case parser.Literal:
+		// Literals are printed as written, so take whichever token is next.
+		p.token(p.peek().Type)
+	case parser.Unary:
+		p.token(e.Operator.Type)
+		p.expr(e.Right)
+	case parser.Variable:
+		p.token(token.Identifier)
+	case parser.Assign:
+		p.token(token.Identifier)
+		p.space()
+		p.token(token.Equal)
+		p.space()
+		p.expr(e.Value)
+	case parser.Call:
+		p.expr(e.Callee)
+		p.token(token.LeftParen)
+		p.list(len(e.Arguments), func(i int) { p.expr(e.Arguments[i]) })
+		p.token(token.RightParen)
+	case parser.Get:
+		p.expr(e.Object)
+		p.token(token.Dot)
+		p.token(token.Identifier)
+	case parser.ListLiteral:
+		p.token(token.LeftBracket)
+		p.list(len(e.Elements), func(i int) { p.expr(e.Elements[i]) })
+		p.token(token.RightBracket)
+	}
+}
+
+// list prints n comma separated items.
+func (p *printer) list(n int, item func(i int)) {
+	for i := range n {
+		if i > 0 {
+			p.token(token.Comma)
+			p.space()
+		}
+		item(i)
+	}
+}
+
+// token prints the next token, which must be of type t, with its trivia.
+func (p *printer) token(t token.Type) token.Token {
+	tok := p.take(t)
+	p.leading(tok)
+	p.write(tok.Lexeme)
+	p.trailing(tok)
+	return tok
+}
+
+func (p *printer) peek() token.Token {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next]
+	}
+	return token.NewToken(token.EOF, "", nil, 0)
+}
+
+func (p *printer) take(t token.Type) token.Token {
+	tok := p.peek()
+	if tok.Type == t {
+		p.next++
+		return tok
+	}
+	// The parser accepts a missing ';' after a variable declaration and a
+	// missing '}' at the end of the input. Printing them fixes the source.
+	switch t {
+	case token.Semicolon:
+		return token.NewToken(t, ";", nil, tok.Line)
+	case token.RightBrace:
+		return token.NewToken(t, "}", nil, tok.Line)
+	}
+	if p.err == nil {
+		p.err = fmt.Errorf("format: expected %s on line %d but found %s", t, tok.Line, tok.Type)
+	}
+	return token.NewToken(t, "", nil, tok.Line)
+}
+
+// leading prints the comments before tok. Those on lines of their own stay
+// there while any in the middle of a statement end the line.
+func (p *printer) leading(tok token.Token) {
+	for _, trivia := range tok.Leading {
+		switch trivia.Kind {
+		case token.BlankLine:
+			if p.lineStart && !p.blockStart {
+				p.blank = true
+			}
+		case token.Comment:
+			if p.lineStart {
+				p.write(trivia.Text)
+				p.newline()
+				p.blockStart = false
+			} else {
+				p.space()
+				p.write(trivia.Text)
+				p.broken = true
+			}
+		}
+	}
+}
+
+func (p *printer) trailing(tok token.Token) {
+	for _, trivia := range tok.Trailing {
+		p.space()
+		p.write(trivia.Text)
+		p.broken = true
+	}
+}
+
+func (p *printer) write(s string) {
+	if p.broken {
+		p.out.WriteString("\n")
+		p.lineStart = true
+		p.broken = false
+		p.continued = true
+	}
+	if p.lineStart {
+		if p.blank {
+			p.out.WriteString("\n")
+			p.blank = false
+		}
+		p.out.WriteString(strings.Repeat(Indent, p.indent))
+		if p.continued {
+			p.out.WriteString(Indent)
+		}
+		p.lineStart = false
+	}
+	p.out.WriteString(s)
+	p.spaced = false
+}
+
+func (p *printer) space() {
+	if !p.lineStart && !p.broken && !p.spaced {
+		p.out.WriteString(" ")
+		p.spaced = true
+	}
+}
+
+func (p *printer) newline() {
+	p.out.WriteString("\n")
+	p.lineStart = true
+	p.broken = false
+	p.continued = false
+}
+
+func hasComments(tok token.Token) bool {
+	for _, trivia := range tok.Leading {
+		if trivia.Kind == token.Comment {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// Package framing reads and writes the messages of the base protocol shared
+// by the Language Server Protocol and the Debug Adapter Protocol: a JSON
+// body preceded by a Content-Length header and a blank line.
+package framing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxLength is the largest body Read accepts, so that a client cannot make
+// the server allocate whatever it claims to send.
+const MaxLength = 64 << 20
+
+// ErrTooLarge is returned for a Content-Length above MaxLength.
+var ErrTooLarge = errors.New("message is too large")
+
+// Read reads the body of one message.
+func Read(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length header")
+	}
+	if length > MaxLength {
+		return nil, fmt.Errorf("%w: Content-Length %d exceeds %d", ErrTooLarge, length, MaxLength)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Write writes message, encoded as JSON, with its header.
+func Write(w io.Writer, message any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
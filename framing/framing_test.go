@@ -0,0 +1,58 @@
+package framing_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/framing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var buf strings.Builder
+	for _, message := range []any{map[string]int{"seq": 1}, "second"} {
+		if err := framing.Write(&buf, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "Content-Length: 9\r\n\r\n{\"seq\":1}Content-Length: 8\r\n\r\n\"second\""; buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+	r := bufio.NewReader(strings.NewReader(buf.String()))
+	for _, want := range []string{`{"seq":1}`, `"second"`} {
+		body, err := framing.Read(r)
+		if err != nil || string(body) != want {
+			t.Errorf("read %q, %v, want %q", body, err, want)
+		}
+	}
+	if _, err := framing.Read(r); !errors.Is(err, io.EOF) {
+		t.Errorf("read past the end: %v", err)
+	}
+}
+
+func TestReadHeaders(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("content-length: 2\nContent-Type: application/json\r\n\r\n{}"))
+	if body, err := framing.Read(r); err != nil || string(body) != "{}" {
+		t.Errorf("read %q, %v", body, err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, input := range []string{
+		"\r\n{}",
+		"Content-Length: two\r\n\r\n{}",
+		"Content-Length: -1\r\n\r\n{}",
+		"Content-Length: 5\r\n\r\n{}",
+	} {
+		if body, err := framing.Read(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("%q: read %q", input, body)
+		}
+	}
+	huge := "Content-Length: " + strconv.Itoa(framing.MaxLength+1) + "\r\n\r\n"
+	if _, err := framing.Read(bufio.NewReader(strings.NewReader(huge))); !errors.Is(err, framing.ErrTooLarge) {
+		t.Errorf("got %v, want ErrTooLarge", err)
+	}
+}
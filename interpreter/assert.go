@@ -0,0 +1,154 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// DefaultTolerance is the tolerance of assert.approx when none is given.
+const DefaultTolerance = 1e-9
+
+func assertModule() types.Module {
+	return types.Module{Name: "assert", Members: map[string]types.ClavType{
+		"ok": native("assert.ok", 1, func(args []types.ClavType) (types.ClavType, error) {
+			if b, ok := args[0].(types.Boolean); !ok || !b.Value {
+				return nil, errors.New("assert.ok: expected true, got " + repr(args[0]))
+			}
+			return types.Nil{}, nil
+		}),
+		"equal": native("assert.equal", 2, func(args []types.ClavType) (types.ClavType, error) {
+			if !valuesEqual(args[0], args[1]) {
+				return nil, fmt.Errorf("assert.equal: expected %s, got %s", repr(args[1]), repr(args[0]))
+			}
+			return types.Nil{}, nil
+		}),
+		"notEqual": native("assert.notEqual", 2, func(args []types.ClavType) (types.ClavType, error) {
+			if valuesEqual(args[0], args[1]) {
+				return nil, errors.New("assert.notEqual: both values are " + repr(args[0]))
+			}
+			return types.Nil{}, nil
+		}),
+		"approx": native("assert.approx", types.Variadic, assertApprox),
+		"throws": native("assert.throws", 1, assertThrows),
+	}}
+}
+
+// assertApprox checks that two numbers differ by at most a tolerance, given
+// as an optional third argument.
+func assertApprox(args []types.ClavType) (types.ClavType, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("assert.approx: expected 2 or 3 arguments")
+	}
+	tolerance := DefaultTolerance
+	numbers := make([]float64, len(args))
+	for j := range args {
+		x, err := numberArg("assert.approx", args, j)
+		if err != nil {
+			return nil, err
+		}
+		numbers[j] = x
+	}
+	if len(numbers) == 3 {
+		tolerance = numbers[2]
+	}
+	if !(math.Abs(numbers[0]-numbers[1]) <= tolerance) {
+		return nil, fmt.Errorf("assert.approx: expected %v within %v, got %v", numbers[1], tolerance, numbers[0])
+	}
+	return types.Nil{}, nil
+}
+
+// assertThrows calls a function that must fail with a runtime error and
+// returns the error message.
+func assertThrows(args []types.ClavType) (types.ClavType, error) {
+	fn, ok := args[0].(types.Callable)
+	if !ok {
+		return nil, argError("assert.throws", 0, "a function", args[0])
+	}
+	if fn.Arity() != 0 && fn.Arity() != types.Variadic {
+		return nil, errors.New("assert.throws: function must take no arguments")
+	}
+	_, err := fn.Call(nil)
+	var runtimeErr InterpreterError
+	if errors.As(err, &runtimeErr) {
+		return types.String{Value: runtimeErr.message}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.New("assert.throws: " + fn.String() + " did not throw")
+}
+
+// valuesEqual compares values structurally. Unlike ==, values of different
+// types are simply unequal.
+func valuesEqual(a, b types.ClavType) bool {
+	return equalValues(a, b, map[[2]types.ClavType]bool{})
+}
+
+// equalValues compares a and b, with comparing holds the pairs of lists and
+// maps being compared further up. Meeting such a pair again means a cycle,
+// which is equal as far as the rest of the comparison can tell.
+func equalValues(a, b types.ClavType, comparing map[[2]types.ClavType]bool) bool {
+	if a == nil {
+		a = types.Nil{}
+	}
+	if b == nil {
+		b = types.Nil{}
+	}
+	switch a.(type) {
+	case *types.List, *types.Map:
+		pair := [2]types.ClavType{a, b}
+		if comparing[pair] {
+			return true
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+	}
+	switch x := a.(type) {
+	case *types.List:
+		y, ok := b.(*types.List)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+		for j := range x.Elements {
+			if !equalValues(x.Elements[j], y.Elements[j], comparing) {
+				return false
+			}
+		}
+		return true
+	case *types.Map:
+		y, ok := b.(*types.Map)
+		if !ok || x.Len() != y.Len() {
+			return false
+		}
+		for _, key := range x.Keys() {
+			xv, _ := x.Get(key)
+			yv, ok := y.Get(key)
+			if !ok || !equalValues(xv, yv, comparing) {
+				return false
+			}
+		}
+		return true
+	case types.Module:
+		y, ok := b.(types.Module)
+		return ok && x.Name == y.Name
+	case types.NativeFunction:
+		// Functions hold Go closures, which are not comparable.
+		return false
+	}
+	return a == b
+}
+
+// repr shows a value in an assertion message, quoting strings.
+func repr(value types.ClavType) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case types.String:
+		return strconv.Quote(v.Value)
+	}
+	return value.String()
+}
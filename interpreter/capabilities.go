@@ -0,0 +1,151 @@
+package interpreter
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Capability names a class of host resources that native modules may touch.
+type Capability string
+
+const (
+	CapRead  Capability = "read"
+	CapWrite Capability = "write"
+	CapEnv   Capability = "env"
+	CapTime  Capability = "time"
+	CapExec  Capability = "exec"
+)
+
+// Capabilities is the set of host resources an Interpreter may use. The zero
+// value denies everything, which is the safe default for embedders.
+type Capabilities struct {
+	// ReadRoots are the directories below which files may be read.
+	ReadRoots []string
+	// WriteRoots are the directories below which files may be written.
+	WriteRoots []string
+	Env        bool
+	Time       bool
+	Exec       bool
+}
+
+// AllCapabilities grants unrestricted access to the host.
+func AllCapabilities() Capabilities {
+	return Capabilities{
+		ReadRoots:  []string{"/"},
+		WriteRoots: []string{"/"},
+		Env:        true,
+		Time:       true,
+		Exec:       true,
+	}
+}
+
+// WithCapabilities sets the host resources available to native modules.
+func WithCapabilities(caps Capabilities) Option {
+	return func(i *Interpreter) {
+		i.capabilities = caps
+	}
+}
+
+// PermissionError is returned by native functions that need a capability
+// the Interpreter was not granted.
+type PermissionError struct {
+	Capability Capability
+	Path       string
+}
+
+func (e PermissionError) Error() string {
+	if e.Path != "" {
+		return "Permission denied: '" + string(e.Capability) + "' capability does not cover " + e.Path
+	}
+	return "Permission denied: '" + string(e.Capability) + "' capability is not granted"
+}
+
+// require returns a PermissionError unless capability is granted.
+func (c Capabilities) require(capability Capability) error {
+	var granted bool
+	switch capability {
+	case CapRead:
+		granted = len(c.ReadRoots) > 0
+	case CapWrite:
+		granted = len(c.WriteRoots) > 0
+	case CapEnv:
+		granted = c.Env
+	case CapTime:
+		granted = c.Time
+	case CapExec:
+		granted = c.Exec
+	}
+	if !granted {
+		return PermissionError{Capability: capability}
+	}
+	return nil
+}
+
+// requirePath returns a PermissionError unless path lies below one of the
+// roots granted for capability, which must be CapRead or CapWrite. Symlinks
+// are followed, so a link below a root cannot lead outside of it.
+func (c Capabilities) requirePath(capability Capability, path string) error {
+	roots := c.ReadRoots
+	if capability == CapWrite {
+		roots = c.WriteRoots
+	}
+	real, err := realPath(path)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(roots, func(root string) bool { return within(root, real) }) {
+		return nil
+	}
+	return PermissionError{Capability: capability, Path: path}
+}
+
+// within reports whether path, which must have been returned by realPath,
+// lies below root.
+func within(root, path string) bool {
+	root, err := realPath(root)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// realPath returns the absolute form of path with every symlink resolved.
+// The parts of path that do not exist yet, like a file about to be written,
+// are kept as they are below the deepest parent that does exist.
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for {
+		real, err := filepath.EvalSymlinks(abs)
+		if err == nil {
+			return filepath.Join(append([]string{real}, missing...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if target, err := os.Readlink(abs); err == nil {
+			// A dangling link: writing to it would create its target.
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(abs), target)
+			}
+			return realPath(filepath.Join(append([]string{target}, missing...)...))
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return filepath.Join(append([]string{abs}, missing...)...), nil
+		}
+		missing = append([]string{filepath.Base(abs)}, missing...)
+		abs = parent
+	}
+}
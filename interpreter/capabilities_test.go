@@ -0,0 +1,106 @@
+package interpreter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+// sandbox creates a data directory that may be read and written, holding
+// symlinks to an outside directory that may not.
+func sandbox(t *testing.T) (data, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	data, outside = filepath.Join(dir, "data"), filepath.Join(dir, "outside")
+	for _, d := range []string{data, outside} {
+		if err := os.Mkdir(d, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "lib.clav"), []byte(`var secret = "secret";`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "inside.txt"), []byte("inside"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"out":        outside,
+		"secret.txt": filepath.Join(outside, "secret.txt"),
+		"lib.clav":   filepath.Join(outside, "lib.clav"),
+		"dangling":   filepath.Join(outside, "created.txt"),
+		"self":       data,
+	} {
+		if err := os.Symlink(target, filepath.Join(data, link)); err != nil {
+			t.Skip("symlinks unsupported:", err)
+		}
+	}
+	return data, outside
+}
+
+func TestSymlinksCannotLeaveRoots(t *testing.T) {
+	data, outside := sandbox(t)
+	caps := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{data}, WriteRoots: []string{data}})
+	for _, call := range []string{
+		`fs.readFile("out/secret.txt")`,
+		`fs.readFile("secret.txt")`,
+		`fs.listDir("out")`,
+		`fs.writeFile("out/new.txt", "x")`,
+		`fs.writeFile("out/sub/new.txt", "x")`,
+		`fs.writeFile("dangling", "x")`,
+		`fs.remove("out/secret.txt")`,
+	} {
+		source := `try { ` + call + `; print "allowed"; } catch (e) { print e; }`
+		out, err := run(t, source, caps, interpreter.WithScriptPath(filepath.Join(data, "main.clav")))
+		if err != nil {
+			t.Fatalf("%s: %v", call, err)
+		}
+		if !strings.HasPrefix(out, "Permission denied") {
+			t.Errorf("%s: %q", call, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "created.txt")); err == nil {
+		t.Error("a dangling link was written through")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+		t.Error("a file outside the roots was removed")
+	}
+}
+
+func TestSymlinksWithinRoots(t *testing.T) {
+	data, _ := sandbox(t)
+	// A root reached through a link is as good as the directory itself.
+	root := filepath.Join(data, "self")
+	caps := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{root}, WriteRoots: []string{root}})
+	source := `
+print fs.readFile(` + strconv.Quote(filepath.Join(data, "inside.txt")) + `);
+print fs.readFile(` + strconv.Quote(filepath.Join(root, "inside.txt")) + `);
+fs.writeFile(` + strconv.Quote(filepath.Join(data, "new", "..", "new.txt")) + `, "new");
+print fs.readFile(` + strconv.Quote(filepath.Join(root, "new.txt")) + `);
+`
+	out, err := run(t, source, caps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "inside\ninside\nnew\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestImportThroughSymlink(t *testing.T) {
+	data, _ := sandbox(t)
+	source := `try { import "lib.clav"; print lib.secret; } catch (e) { print e; }`
+	out, err := run(t, source, interpreter.WithScriptPath(filepath.Join(data, "main.clav")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Permission denied: cannot import lib.clav"; !strings.Contains(out, want) {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,72 @@
+package interpreter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the time module. Embedders can replace
+// the system clock with a FakeClock to make scripts deterministic.
+type Clock interface {
+	// Now returns the current time. Differences between two results must
+	// be monotonic.
+	Now() time.Time
+	// Sleep pauses for d or until ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// WithClock sets the clock used by the time module.
+func WithClock(clock Clock) Option {
+	return func(i *Interpreter) {
+		i.clock = clock
+	}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// FakeClock is a Clock that only moves when told to. Sleeping advances it
+// immediately.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+	c.Advance(d)
+	return nil
+}
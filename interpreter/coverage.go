@@ -0,0 +1,12 @@
+package interpreter
+
+import "github.com/it-a-me/clavlang/coverage"
+
+// WithCoverage counts the statements and branches the Interpreter runs in
+// profile, which may be shared by several Interpreters to add up their
+// coverage.
+func WithCoverage(profile *coverage.Profile) Option {
+	return func(i *Interpreter) {
+		i.coverage = profile
+	}
+}
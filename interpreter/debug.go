@@ -0,0 +1,223 @@
+package interpreter
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// Debugger is called before each statement runs, other than blocks, which
+// only group the statements they contain. It may inspect the Interpreter
+// with Stack, Scopes and Evaluate before returning. A returned error stops
+// the program and is reported as a DebugError.
+type Debugger interface {
+	Statement(i *Interpreter, stmt parser.Stmt) error
+}
+
+// WithDebugger installs d as the Interpreter's debugger.
+func WithDebugger(d Debugger) Option {
+	return func(i *Interpreter) {
+		i.debugger = d
+	}
+}
+
+// DebugError wraps an error returned by a Debugger. Like the limit errors
+// it cannot be caught by the program.
+type DebugError struct {
+	Err error
+}
+
+func (e DebugError) Error() string {
+	return "Execution aborted: " + e.Err.Error()
+}
+
+func (e DebugError) Unwrap() error {
+	return e.Err
+}
+
+// Frame is a function call in progress. The main script and each module
+// being imported have a frame of their own below the calls they make.
+type Frame struct {
+	Function string
+	// File is the absolute path of the file the function is declared in,
+	// or empty for a script not read from a file.
+	File string
+	// Line is the line of the statement the frame is running.
+	Line int
+}
+
+// frame is a Frame together with the environment it runs in. The innermost
+// frame runs in the Interpreter's current environment instead.
+type frame struct {
+	Frame
+	id          uint64
+	environment Environment
+}
+
+// Stack returns the frames in progress, innermost first.
+func (i *Interpreter) Stack() []Frame {
+	stack := make([]Frame, len(i.frames))
+	for j, f := range i.frames {
+		stack[len(i.frames)-1-j] = f.Frame
+	}
+	return stack
+}
+
+// FrameID identifies the innermost frame. Each frame pushed while the
+// Interpreter runs has an ID of its own, so that two calls of a function
+// can be told apart even at the same depth. The main script's frame is 0.
+func (i *Interpreter) FrameID() uint64 {
+	return i.frames[len(i.frames)-1].id
+}
+
+// Scopes returns copies of the scopes visible in the frame at index frame
+// of Stack, innermost first. The standard library modules are left out.
+func (i *Interpreter) Scopes(frame int) []map[string]types.ClavType {
+	env, ok := i.frameEnvironment(frame)
+	if !ok {
+		return nil
+	}
+	scopes := make([]map[string]types.ClavType, 0, len(env.env))
+	for _, scope := range env.env {
+		vars := make(map[string]types.ClavType, len(scope))
+		for name, value := range scope {
+			if !i.isStdlib(value) {
+				vars[name] = value
+			}
+		}
+		if len(vars) == 0 && len(scope) > 0 {
+			// Modules keep the standard library in a scope of its own.
+			continue
+		}
+		scopes = append([]map[string]types.ClavType{vars}, scopes...)
+	}
+	return scopes
+}
+
+// Evaluate evaluates the expression in source in the scopes of the frame
+// at index frame of Stack. The debugger is not called for any statements
+// it runs.
+func (i *Interpreter) Evaluate(frame int, source string) (types.ClavType, error) {
+	expr, err := ParseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	env, ok := i.frameEnvironment(frame)
+	if !ok {
+		return nil, errors.New("no such frame")
+	}
+	previous, paused := i.environment, i.paused
+	i.environment, i.paused = env, true
+	defer func() { i.environment, i.paused = previous, paused }()
+	return i.evaluate(expr)
+}
+
+// ParseExpression parses source, which must hold a single expression.
+func ParseExpression(source string) (parser.Expr, error) {
+	s := scanner.NewScanner(source + ";")
+	tokens, errs := s.Scan()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	if len(stmts) == 1 {
+		if stmt, ok := stmts[0].(parser.Expression); ok {
+			return stmt.Inner, nil
+		}
+	}
+	return nil, errors.New("expected a single expression")
+}
+
+func (i *Interpreter) frameEnvironment(index int) (Environment, bool) {
+	if index < 0 || index >= len(i.frames) {
+		return Environment{}, false
+	}
+	if index == 0 {
+		return i.environment, true
+	}
+	return i.frames[len(i.frames)-1-index].environment, true
+}
+
+// pushFrame records a call to function, declared in file, unless it would
+// exceed the depth limit. It must happen before the call switches
+// environments and, when it succeeds, be paired with popFrame.
+func (i *Interpreter) pushFrame(function, file string) error {
+	if err := i.checkDepth(); err != nil {
+		return err
+	}
+	i.frames[len(i.frames)-1].environment = i.environment
+	i.calls++
+	i.frames = append(i.frames, frame{Frame: Frame{Function: function, File: file}, id: i.calls})
+	if i.profiler != nil {
+		i.profiler.PushFrame(i, function, file)
+	}
+	return nil
+}
+
+func (i *Interpreter) popFrame() {
+	i.frames = i.frames[:len(i.frames)-1]
+	i.frames[len(i.frames)-1].environment = Environment{}
+	if i.profiler != nil {
+		i.profiler.PopFrame(i)
+	}
+}
+
+// currentFile is the absolute path of the file being run, if known.
+func (i *Interpreter) currentFile() string {
+	if len(i.importing) == 0 {
+		return ""
+	}
+	return i.importing[len(i.importing)-1]
+}
+
+// visit records that stmt is about to run, as the line of the innermost
+// frame, in the coverage profile, with the profiler and the tracer, and
+// passes it to the debugger unless the debugger is the one running it.
+func (i *Interpreter) visit(stmt parser.Stmt) error {
+	if _, ok := stmt.(parser.Block); ok {
+		return nil
+	}
+	top := &i.frames[len(i.frames)-1]
+	top.Line = parser.StmtToken(stmt).Line
+	if i.coverage != nil {
+		i.coverage.Statement(top.File, top.Line)
+	}
+	if i.profiler != nil {
+		i.profiler.Statement(i, top.Line)
+	}
+	if i.tracer != nil {
+		i.tracer.Statement(i, stmt)
+	}
+	if i.debugger == nil || i.paused {
+		return nil
+	}
+	i.paused = true
+	defer func() { i.paused = false }()
+	if err := i.debugger.Statement(i, stmt); err != nil {
+		return DebugError{Err: err}
+	}
+	return nil
+}
+
+func (i *Interpreter) isStdlib(value types.ClavType) bool {
+	module, ok := value.(types.Module)
+	if !ok {
+		return false
+	}
+	// Modules are compared by identity so that a script's own module of
+	// the same name is still shown.
+	members := reflect.ValueOf(module.Members).UnsafePointer()
+	for _, m := range i.stdlib {
+		if reflect.ValueOf(m.Members).UnsafePointer() == members {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,17 @@ func NewEnvironment() Environment {
 	return Environment{env: env}
 }
 
+// Capture returns an Environment sharing the scopes currently visible in e.
+// Variables later defined or assigned in those scopes are seen by both.
+func (e *Environment) Capture() Environment {
+	return Environment{env: slices.Clip(e.env)}
+}
+
+// Extend returns a copy of e with scope pushed as its innermost scope.
+func (e *Environment) Extend(scope map[string]types.ClavType) Environment {
+	return Environment{env: append(slices.Clip(e.env), scope)}
+}
+
 func (e *Environment) NewScope() {
 	e.env = append(e.env, map[string]types.ClavType{})
 }
@@ -0,0 +1,304 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// The exec module runs subprocesses. Every function requires the exec
+// capability, so embedded interpreters never spawn processes by default.
+//
+// Functions accept an optional options map with the keys "stdin" (a String
+// fed to the first process), "env" (a Map of extra variables) and "dir" (the
+// working directory, relative to the script directory).
+func (i *Interpreter) execModule() types.Module {
+	return types.Module{Name: "exec", Members: map[string]types.ClavType{
+		"run":    native("exec.run", types.Variadic, i.execRun),
+		"stream": native("exec.stream", types.Variadic, i.execStream),
+		"pipe":   native("exec.pipe", types.Variadic, i.execPipe),
+	}}
+}
+
+// execRun runs a command to completion and returns a map holding its
+// stdout, stderr and exit code.
+func (i *Interpreter) execRun(args []types.ClavType) (types.ClavType, error) {
+	cmd, err := i.command("exec.run", args)
+	if err != nil {
+		return nil, err
+	}
+	output := i.newOutputLimit()
+	stdout, stderr := output.buffer(), output.buffer()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	code, err := i.wait("exec.run", cmd.Run())
+	if err != nil {
+		return nil, err
+	}
+	if err := output.check(); err != nil {
+		return nil, err
+	}
+	return i.execResult(stdout.String(), stderr.String(), code)
+}
+
+// execStream runs a command with its output connected to the interpreter's
+// output as it is produced and returns the exit code.
+func (i *Interpreter) execStream(args []types.ClavType) (types.ClavType, error) {
+	cmd, err := i.command("exec.stream", args)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = i.stdout
+	cmd.Stderr = os.Stderr
+	code, err := i.wait("exec.stream", cmd.Run())
+	if err != nil {
+		return nil, err
+	}
+	return types.Number{Value: float64(code)}, nil
+}
+
+// execPipe runs a pipeline. Its first argument is a list of commands, each
+// itself a list of the program name followed by its arguments. The result
+// is the stdout of the last command, the stderr of all of them and the exit
+// code of the last.
+func (i *Interpreter) execPipe(args []types.ClavType) (types.ClavType, error) {
+	const fn = "exec.pipe"
+	if err := i.capabilities.require(CapExec); err != nil {
+		return nil, err
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New(fn + ": expected 1 or 2 arguments")
+	}
+	stages, err := listArg(fn, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(stages.Elements) == 0 {
+		return nil, errors.New(fn + ": pipeline is empty")
+	}
+	output := i.newOutputLimit()
+	stdout := output.buffer()
+	// Each command gets a buffer of its own as they all run at once.
+	stderr := make([]*limitedBuffer, len(stages.Elements))
+	cmds := make([]*exec.Cmd, len(stages.Elements))
+	var previous io.Reader
+	for j, stage := range stages.Elements {
+		argv, err := stringsArg(fn, []types.ClavType{stage}, 0)
+		if err != nil || len(argv) == 0 {
+			return nil, errors.New(fn + ": each command must be a non-empty list of Strings")
+		}
+		stageArgs := []types.ClavType{types.String{Value: argv[0]}, stringList(argv[1:])}
+		if len(args) == 2 {
+			stageArgs = append(stageArgs, args[1])
+		}
+		cmd, err := i.command(fn, stageArgs)
+		if err != nil {
+			return nil, err
+		}
+		if j > 0 {
+			cmd.Stdin = previous
+		}
+		if j == len(cmds)-1 {
+			cmd.Stdout = stdout
+		} else if previous, err = cmd.StdoutPipe(); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+		stderr[j] = output.buffer()
+		cmd.Stderr = stderr[j]
+		cmds[j] = cmd
+	}
+	for j, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			for _, started := range cmds[:j] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+			return nil, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+	// Every command is waited for, even after one failed, so that none is
+	// left running.
+	var code int
+	var first error
+	for _, cmd := range cmds {
+		if code, err = i.wait(fn, cmd.Wait()); err != nil && first == nil {
+			first = err
+		}
+	}
+	if first != nil {
+		return nil, first
+	}
+	if err := output.check(); err != nil {
+		return nil, err
+	}
+	var stderrs strings.Builder
+	for j := range stderr {
+		stderrs.Write(stderr[j].Bytes())
+	}
+	return i.execResult(stdout.String(), stderrs.String(), code)
+}
+
+// command builds a command from the arguments (name, args, options?).
+func (i *Interpreter) command(fn string, args []types.ClavType) (*exec.Cmd, error) {
+	if err := i.capabilities.require(CapExec); err != nil {
+		return nil, err
+	}
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New(fn + ": expected 2 or 3 arguments")
+	}
+	name, err := stringArg(fn, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	argv, err := stringsArg(fn, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(i.ctx, name, argv...)
+	cmd.Dir = i.scriptDir
+	if len(args) < 3 {
+		return cmd, nil
+	}
+	options, ok := args[2].(*types.Map)
+	if !ok {
+		return nil, argError(fn, 2, "a Map", args[2])
+	}
+	if stdin, ok := options.Get("stdin"); ok {
+		text, ok := stdin.(types.String)
+		if !ok {
+			return nil, errors.New(fn + ": option stdin must be a String")
+		}
+		cmd.Stdin = strings.NewReader(text.Value)
+	}
+	if dir, ok := options.Get("dir"); ok {
+		text, ok := dir.(types.String)
+		if !ok {
+			return nil, errors.New(fn + ": option dir must be a String")
+		}
+		cmd.Dir = text.Value
+		if !filepath.IsAbs(cmd.Dir) {
+			cmd.Dir = filepath.Join(i.scriptDir, cmd.Dir)
+		}
+	}
+	if env, ok := options.Get("env"); ok {
+		vars, ok := env.(*types.Map)
+		if !ok {
+			return nil, errors.New(fn + ": option env must be a Map")
+		}
+		cmd.Env = os.Environ()
+		for _, key := range vars.Keys() {
+			value, _ := vars.Get(key)
+			text, ok := value.(types.String)
+			if !ok {
+				return nil, errors.New(fn + ": environment variable " + key + " must be a String")
+			}
+			cmd.Env = append(cmd.Env, key+"="+text.Value)
+		}
+	}
+	return cmd, nil
+}
+
+// wait turns the error from running a command into its exit code. Failing
+// to start, or being cancelled, is an error.
+func (i *Interpreter) wait(fn string, err error) (int, error) {
+	if ctxErr := i.ctx.Err(); ctxErr != nil {
+		return 0, CanceledError{Err: ctxErr}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", fn, err)
+	}
+	return 0, nil
+}
+
+// outputLimit is the memory the output of one exec call may take, shared by
+// the buffers collecting it. Output past it is dropped rather than held, so
+// a command cannot exhaust the host before the memory limit is checked.
+type outputLimit struct {
+	// limit is Limits.MaxMemory, for the error.
+	limit    int
+	limited  bool
+	mu       sync.Mutex
+	left     int
+	exceeded bool
+}
+
+func (i *Interpreter) newOutputLimit() *outputLimit {
+	left, limited := i.memoryLeft()
+	return &outputLimit{left: left, limited: limited, limit: i.limits.MaxMemory}
+}
+
+func (l *outputLimit) buffer() *limitedBuffer {
+	return &limitedBuffer{limit: l}
+}
+
+// check reports whether output was dropped.
+func (l *outputLimit) check() error {
+	if l.exceeded {
+		return MemoryLimitError{Limit: l.limit}
+	}
+	return nil
+}
+
+// limitedBuffer is a bytes.Buffer that only keeps what its outputLimit
+// allows. Commands write to their buffers concurrently.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit *outputLimit
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	l := b.limit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(p)
+	if l.limited {
+		if len(p) > l.left {
+			p = p[:l.left]
+			l.exceeded = true
+		}
+		l.left -= len(p)
+	}
+	// Writes to a bytes.Buffer cannot fail.
+	_, _ = b.Buffer.Write(p)
+	return n, nil
+}
+
+func (i *Interpreter) execResult(stdout, stderr string, code int) (types.ClavType, error) {
+	if err := i.reserve(len(stdout) + len(stderr)); err != nil {
+		return nil, err
+	}
+	result := types.NewMap()
+	result.Set("stdout", types.String{Value: stdout})
+	result.Set("stderr", types.String{Value: stderr})
+	result.Set("code", types.Number{Value: float64(code)})
+	return result, nil
+}
+
+// stringsArg returns an argument that must be a list of Strings.
+func stringsArg(fn string, args []types.ClavType, index int) ([]string, error) {
+	list, err := listArg(fn, args, index)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(list.Elements))
+	for j, e := range list.Elements {
+		s, ok := e.(types.String)
+		if !ok {
+			return nil, argError(fn, index, "a List of Strings", args[index])
+		}
+		values[j] = s.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,154 @@
+package interpreter_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+// runExec runs source with the exec capability from a script in dir.
+func runExec(t *testing.T, dir, source string) string {
+	t.Helper()
+	for _, name := range []string{"sh", "cat", "sort", "head", "pwd"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skip("exec tests need", name)
+		}
+	}
+	out, err := run(t, source,
+		interpreter.WithCapabilities(interpreter.Capabilities{Exec: true}),
+		interpreter.WithScriptDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestExecRun(t *testing.T) {
+	source := `
+var result = exec.run("sh", ["-c", "echo out; echo err >&2; exit 3"]);
+print result.get("stdout").trim();
+print result.get("stderr").trim();
+print result.get("code");
+print exec.run("true", []).get("code");
+`
+	if out, want := runExec(t, t.TempDir(), source), "out\nerr\n3\n0\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestExecOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	source := `
+var options = json.parse("{}");
+options.set("stdin", "fed to cat");
+print exec.run("cat", [], options).get("stdout");
+var env = json.parse("{}");
+env.set("CLAV_EXEC_TEST", "from env");
+options = json.parse("{}");
+options.set("env", env);
+print exec.run("sh", ["-c", "echo $CLAV_EXEC_TEST"], options).get("stdout").trim();
+options = json.parse("{}");
+options.set("dir", "sub");
+print exec.run("pwd", [], options).get("stdout").trim();
+print exec.run("pwd", []).get("stdout").trim();
+`
+	out := runExec(t, dir, source)
+	// The working directory may be reported through symlinks.
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 4 || lines[0] != "fed to cat" || lines[1] != "from env" {
+		t.Fatalf("got %q", out)
+	}
+	for j, want := range []string{filepath.Join(dir, "sub"), dir} {
+		got := lines[2+j]
+		if got != want && got != strings.Replace(want, dir, real, 1) {
+			t.Errorf("working directory %q, want %q", got, want)
+		}
+	}
+}
+
+func TestExecStream(t *testing.T) {
+	source := `
+var code = exec.stream("sh", ["-c", "echo streamed; exit 2"]);
+print code;
+`
+	if out, want := runExec(t, t.TempDir(), source), "streamed\n2\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestExecPipe(t *testing.T) {
+	source := `
+var result = exec.pipe([
+  ["sh", "-c", "printf 'b\na\nc\n'; echo first >&2"],
+  ["sort"],
+  ["head", "-n", "2"]
+]);
+print result.get("stdout");
+print result.get("stderr").trim();
+print result.get("code");
+var options = json.parse("{}");
+options.set("stdin", "piped");
+print exec.pipe([["cat"], ["sh", "-c", "cat; exit 4"]], options).get("code");
+`
+	if out, want := runExec(t, t.TempDir(), source), "a\nb\n\nfirst\n0\n4\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	source := `
+try { exec.run("clav-no-such-binary", []); } catch (e) { print e; }
+try { exec.pipe([["true"], ["clav-no-such-binary"]]); } catch (e) { print e; }
+try { exec.pipe([]); } catch (e) { print e; }
+try { exec.run("true", [1]); } catch (e) { print e; }
+`
+	out := runExec(t, t.TempDir(), source)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %q", out)
+	}
+	for j, want := range []string{
+		"exec.run: exec: \"clav-no-such-binary\": executable file not found",
+		"exec.pipe: exec: \"clav-no-such-binary\": executable file not found",
+		"exec.pipe: pipeline is empty",
+		"exec.run",
+	} {
+		if !strings.HasPrefix(lines[j], want) {
+			t.Errorf("error %q, want it to start with %q", lines[j], want)
+		}
+	}
+}
+
+func TestExecMemoryLimit(t *testing.T) {
+	for _, name := range []string{"sh", "head", "cat"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skip("exec tests need", name)
+		}
+	}
+	opts := []interpreter.Option{
+		interpreter.WithCapabilities(interpreter.Capabilities{Exec: true}),
+		interpreter.WithLimits(interpreter.Limits{MaxMemory: 1 << 16}),
+	}
+	for _, source := range []string{
+		`exec.run("head", ["-c", "1000000", "/dev/zero"]);`,
+		`exec.run("sh", ["-c", "head -c 1000000 /dev/zero >&2"]);`,
+		`exec.pipe([["head", "-c", "1000000", "/dev/zero"], ["cat"]]);`,
+	} {
+		i := interpreter.NewInterpreter(opts...)
+		if err := i.Interpret(parse(t, source)); !errors.As(err, new(interpreter.MemoryLimitError)) {
+			t.Errorf("%s: got error %v, want a MemoryLimitError", source, err)
+		}
+	}
+}
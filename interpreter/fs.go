@@ -0,0 +1,209 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// WithScriptDir sets the directory relative paths given to the fs module are
+// resolved against, normally the directory of the script being run.
+func WithScriptDir(dir string) Option {
+	return func(i *Interpreter) {
+		i.scriptDir = dir
+	}
+}
+
+func (i *Interpreter) fsModule() types.Module {
+	return types.Module{Name: "fs", Members: map[string]types.ClavType{
+		"readFile":   native("fs.readFile", 1, i.fsReadFile),
+		"readLines":  native("fs.readLines", 1, i.fsReadLines),
+		"writeFile":  native("fs.writeFile", 2, i.fsWriter("fs.writeFile", os.O_TRUNC)),
+		"appendFile": native("fs.appendFile", 2, i.fsWriter("fs.appendFile", os.O_APPEND)),
+		"listDir":    native("fs.listDir", 1, i.fsListDir),
+		"exists":     native("fs.exists", 1, i.fsExists),
+		"stat":       native("fs.stat", 1, i.fsStat),
+		"remove":     native("fs.remove", 1, i.fsRemove),
+		"mkdirAll":   native("fs.mkdirAll", 1, i.fsMkdirAll),
+	}}
+}
+
+// fsPath resolves the path argument at index against the script directory
+// and checks it is covered by capability.
+func (i *Interpreter) fsPath(fn string, args []types.ClavType, index int, capability Capability) (string, error) {
+	path, err := stringArg(fn, args, index)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(i.scriptDir, path)
+	}
+	if err := i.capabilities.requirePath(capability, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// readFile reads a whole file, refusing files that would exceed the
+// memory limit. Special files report a size of 0 however much they hold,
+// so reading also stops one byte past the memory left.
+func (i *Interpreter) readFile(fn string, args []types.ClavType) (string, error) {
+	path, err := i.fsPath(fn, args, 0, CapRead)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fsError(fn, err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fsError(fn, err)
+	}
+	if err := i.reserve(int(info.Size())); err != nil {
+		return "", err
+	}
+	var r io.Reader = f
+	if left, limited := i.memoryLeft(); limited {
+		r = io.LimitReader(f, int64(left)+1)
+	}
+	bytes, err := io.ReadAll(r)
+	if err != nil {
+		return "", fsError(fn, err)
+	}
+	if err := i.reserve(len(bytes)); err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func (i *Interpreter) fsReadFile(args []types.ClavType) (types.ClavType, error) {
+	text, err := i.readFile("fs.readFile", args)
+	if err != nil {
+		return nil, err
+	}
+	return types.String{Value: text}, nil
+}
+
+func (i *Interpreter) fsReadLines(args []types.ClavType) (types.ClavType, error) {
+	text, err := i.readFile("fs.readLines", args)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return types.NewList(), nil
+	}
+	lines := strings.Split(text, "\n")
+	for j, line := range lines {
+		lines[j] = strings.TrimSuffix(line, "\r")
+	}
+	return stringList(lines), nil
+}
+
+func (i *Interpreter) fsWriter(fn string, mode int) func([]types.ClavType) (types.ClavType, error) {
+	return func(args []types.ClavType) (types.ClavType, error) {
+		path, err := i.fsPath(fn, args, 0, CapWrite)
+		if err != nil {
+			return nil, err
+		}
+		text, err := stringArg(fn, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|mode, filePerm)
+		if err != nil {
+			return nil, fsError(fn, err)
+		}
+		_, err = f.WriteString(text)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fsError(fn, err)
+		}
+		return types.Nil{}, nil
+	}
+}
+
+func (i *Interpreter) fsListDir(args []types.ClavType) (types.ClavType, error) {
+	path, err := i.fsPath("fs.listDir", args, 0, CapRead)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fsError("fs.listDir", err)
+	}
+	names := make([]string, len(entries))
+	for j, entry := range entries {
+		names[j] = entry.Name()
+	}
+	return stringList(names), nil
+}
+
+func (i *Interpreter) fsExists(args []types.ClavType) (types.ClavType, error) {
+	path, err := i.fsPath("fs.exists", args, 0, CapRead)
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fsError("fs.exists", err)
+	}
+	return types.Boolean{Value: err == nil}, nil
+}
+
+func (i *Interpreter) fsStat(args []types.ClavType) (types.ClavType, error) {
+	path, err := i.fsPath("fs.stat", args, 0, CapRead)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fsError("fs.stat", err)
+	}
+	stat := types.NewMap()
+	stat.Set("name", types.String{Value: info.Name()})
+	stat.Set("size", types.Number{Value: float64(info.Size())})
+	stat.Set("isDir", types.Boolean{Value: info.IsDir()})
+	stat.Set("modified", types.Number{Value: float64(info.ModTime().UnixMilli()) / 1000})
+	return stat, nil
+}
+
+func (i *Interpreter) fsRemove(args []types.ClavType) (types.ClavType, error) {
+	path, err := i.fsPath("fs.remove", args, 0, CapWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fsError("fs.remove", err)
+	}
+	return types.Nil{}, nil
+}
+
+func (i *Interpreter) fsMkdirAll(args []types.ClavType) (types.ClavType, error) {
+	path, err := i.fsPath("fs.mkdirAll", args, 0, CapWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(path, dirPerm); err != nil {
+		return nil, fsError("fs.mkdirAll", err)
+	}
+	return types.Nil{}, nil
+}
+
+func fsError(fn string, err error) error {
+	return errors.New(fn + ": " + err.Error())
+}
@@ -0,0 +1,79 @@
+package interpreter_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestFS(t *testing.T) {
+	dir := t.TempDir()
+	caps := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{dir}, WriteRoots: []string{dir}})
+	source := `
+print fs.exists("out.txt");
+fs.writeFile("out.txt", "one
+two
+");
+fs.appendFile("out.txt", "three
+");
+print fs.readLines("out.txt");
+print fs.readFile("out.txt").length();
+var info = fs.stat("out.txt");
+print info.get("name");
+print info.get("size");
+print info.get("isDir");
+fs.mkdirAll("sub/dir");
+print fs.stat("sub").get("isDir");
+print fs.listDir(".");
+fs.remove("out.txt");
+print fs.exists("out.txt");
+`
+	out, err := run(t, source, caps, interpreter.WithScriptDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "false\n[one, two, three]\n14\nout.txt\n14\nfalse\ntrue\n[out.txt, sub]\nfalse\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub", "dir")); err != nil {
+		t.Errorf("mkdirAll: %v", err)
+	}
+}
+
+func TestFSErrors(t *testing.T) {
+	dir := t.TempDir()
+	readOnly := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{dir}})
+	for source, want := range map[string]string{
+		`fs.readFile("missing.txt");`:   "no such file or directory",
+		`fs.readFile("/etc/hostname");`: "Permission denied: 'read' capability does not cover /etc/hostname",
+		`fs.writeFile("new.txt", "");`:  "Permission denied",
+		`fs.readFile(1);`:               "fs.readFile: argument 1 must be a String",
+		`try { fs.remove("x"); } catch (e) { print e; } fs.listDir("missing");`: "fs.listDir",
+	} {
+		_, err := run(t, source, readOnly, interpreter.WithScriptDir(dir))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", source, err, want)
+		}
+	}
+	// The script cannot reach the host without a capability.
+	if _, err := run(t, `fs.exists(".");`, interpreter.WithScriptDir(dir)); err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("no capabilities: got error %v", err)
+	}
+}
+
+func TestReadFileMemoryLimit(t *testing.T) {
+	if _, err := os.Stat("/dev/zero"); err != nil {
+		t.Skip(err)
+	}
+	// /dev/zero reports a size of 0 but never ends.
+	caps := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{"/dev"}})
+	limits := interpreter.WithLimits(interpreter.Limits{MaxMemory: 1 << 16})
+	if _, err := run(t, `fs.readFile("/dev/zero");`, caps, limits); !errors.As(err, new(interpreter.MemoryLimitError)) {
+		t.Errorf("got error %v, want a MemoryLimitError", err)
+	}
+}
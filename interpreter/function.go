@@ -0,0 +1,65 @@
+package interpreter
+
+import (
+	"errors"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// Function is a function declared by a script, together with the scopes
+// that were visible where it was declared.
+type Function struct {
+	types.Foreign
+	declaration parser.Function
+	closure     Environment
+	interpreter *Interpreter
+	file        string
+}
+
+func (f *Function) String() string {
+	return "<fn " + f.declaration.Name.Lexeme + ">"
+}
+
+func (f *Function) Arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *Function) Call(args []types.ClavType) (types.ClavType, error) {
+	i := f.interpreter
+	params := make(map[string]types.ClavType, len(args))
+	for j, param := range f.declaration.Params {
+		params[param.Lexeme] = args[j]
+	}
+	if err := i.pushFrame(f.declaration.Name.Lexeme, f.file); err != nil {
+		return nil, err
+	}
+	defer i.popFrame()
+	previous := i.environment
+	i.environment = f.closure.Extend(params)
+	defer func() { i.environment = previous }()
+	scope := "call " + f.declaration.Name.Lexeme
+	i.pushScope(scope)
+	defer i.popScope(scope)
+
+	err := i.executeStatements(f.declaration.Body)
+	var ret returnSignal
+	if errors.As(err, &ret) {
+		return ret.value, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return types.Nil{}, nil
+}
+
+// returnSignal unwinds the statements of a function body up to its call.
+type returnSignal struct {
+	keyword token.Token
+	value   types.ClavType
+}
+
+func (r returnSignal) Error() string {
+	return newInterpreterError("Cannot return from top-level code", r.keyword).Error()
+}
@@ -0,0 +1,75 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClosures(t *testing.T) {
+	source := `
+fun counter() {
+  var count = 0;
+  fun next() {
+    count = count + 1;
+    return count;
+  }
+  return next;
+}
+var a = counter();
+var b = counter();
+print a();
+print a();
+print b();
+var greeting = "hello";
+fun greet() { return greeting; }
+greeting = "bye";
+print greet();
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n2\n1\nbye\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestRecursion(t *testing.T) {
+	source := `
+fun sum(items, n) {
+  try { items.get(n); } catch (e) { return 0; }
+  return items.get(n) + sum(items, n + 1);
+}
+print sum([1, 2, 3, 4], 0);
+fun none() { return; }
+print none();
+fun early(n) {
+  { { return n; } }
+  print "unreachable";
+}
+print early(3);
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "10\nnil\n3\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestReturnOutsideFunction(t *testing.T) {
+	for _, source := range []string{
+		`return 1;`,
+		`{ return; }`,
+		`try { return 1; } catch (e) { print e; }`,
+	} {
+		out, err := run(t, source)
+		if err == nil || !strings.Contains(err.Error(), "Cannot return from top-level code") {
+			t.Errorf("%s: got error %v", source, err)
+		}
+		if out != "" {
+			t.Errorf("%s: printed %q", source, out)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package interpreter_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+// FuzzRun interprets every program that parses. The limits keep runaway
+// loops, recursion and allocations short, and no capabilities are granted.
+func FuzzRun(f *testing.F) {
+	f.Add(`var x = 1; x = x + 1; print x;`)
+	f.Add(`fun f(n) { return f(n + 1); } f(0);`)
+	f.Add(`var l = [1, "a", nil]; l.push(l); print l.length();`)
+	f.Add(`print -"a"; print !1; print nil;`)
+	f.Add(`try { json.parse("[1,"); } catch (e) { print e; }`)
+	f.Add(`print "ab".repeat(1000000000);`)
+	f.Fuzz(func(t *testing.T, source string) {
+		s := scanner.NewScanner(source)
+		tokens, errs := s.Scan()
+		if errs != nil {
+			return
+		}
+		p := parser.NewParser(tokens)
+		stmts, errs := p.Parse()
+		if errs != nil {
+			return
+		}
+		inter := interpreter.NewInterpreter(
+			interpreter.WithStdout(io.Discard),
+			interpreter.WithSeed(1),
+			interpreter.WithLimits(interpreter.Limits{MaxSteps: 10000, MaxDepth: 200, MaxMemory: 1 << 20}),
+		)
+		_ = inter.Interpret(stmts)
+	})
+}
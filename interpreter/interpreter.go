@@ -1,46 +1,135 @@
 package interpreter
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"reflect"
+	"io"
+	"math/rand/v2"
+	"os"
 
+	"github.com/it-a-me/clavlang/coverage"
 	"github.com/it-a-me/clavlang/parser"
 	"github.com/it-a-me/clavlang/token"
 	"github.com/it-a-me/clavlang/types"
 )
 
 func (i *Interpreter) Interpret(statements []parser.Stmt) error {
-	for _, stmt := range statements {
-		if err := i.execute(stmt); err != nil {
-			return err
-		}
+	return i.InterpretContext(context.Background(), statements)
+}
+
+// InterpretContext executes statements until they finish, an error occurs or
+// ctx is done. Cancellation is reported as a CanceledError.
+func (i *Interpreter) InterpretContext(ctx context.Context, statements []parser.Stmt) error {
+	i.ctx = ctx
+	defer func() { i.ctx = nil }()
+	if i.coverage != nil {
+		i.coverage.Register(i.currentFile(), statements)
 	}
-	return nil
+	err := i.executeStatements(statements)
+	var ret returnSignal
+	if errors.As(err, &ret) {
+		return newInterpreterError("Cannot return from top-level code", ret.keyword)
+	}
+	return err
+}
+
+// Call calls the global function name with args once a script has been
+// interpreted, for example to run a test function it declared.
+func (i *Interpreter) Call(ctx context.Context, name string, args ...types.ClavType) (types.ClavType, error) {
+	i.ctx = ctx
+	defer func() { i.ctx = nil }()
+	callee, err := i.environment.Get(token.Token{Type: token.Identifier, Lexeme: name})
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := callee.(types.Callable)
+	if !ok {
+		return nil, errors.New("'" + name + "' is not a function")
+	}
+	if fn.Arity() != types.Variadic && fn.Arity() != len(args) {
+		return nil, fmt.Errorf("'%s' expects %d arguments but got %d", name, fn.Arity(), len(args))
+	}
+	return fn.Call(args)
 }
 
 type Interpreter struct {
-	environment Environment
+	environment  Environment
+	limits       Limits
+	capabilities Capabilities
+	scriptDir    string
+	searchPath   []string
+	packages     map[string]string
+	args         []string
+	stdout       io.Writer
+	clock        Clock
+	random       *rand.Rand
+	ctx          context.Context
+
+	stdlib []types.Module
+	// modules caches imported modules by absolute path and importing is the
+	// chain of files currently being imported, starting at the main script.
+	modules   map[string]types.Module
+	importing []string
+
+	steps     int
+	allocated int
+
+	// frames holds the calls in progress, the main script first, and calls
+	// counts the frames ever pushed to give each its ID.
+	frames   []frame
+	calls    uint64
+	debugger Debugger
+	paused   bool
+	coverage *coverage.Profile
+	profiler Profiler
+	tracer   Tracer
+}
+
+// NewInterpreter returns a pointer as native modules keep a reference to
+// the Interpreter they were defined in.
+func NewInterpreter(opts ...Option) *Interpreter {
+	i := &Interpreter{
+		environment: NewEnvironment(),
+		stdout:      os.Stdout,
+		clock:       systemClock{},
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	i.frames = []frame{{Frame: Frame{Function: "<script>", File: i.currentFile()}}}
+	i.defineStdlib()
+	return i
 }
 
-func NewInterpreter() Interpreter {
-	return Interpreter{environment: NewEnvironment()}
+// WithStdout redirects the output of print statements.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = w
+	}
 }
 
 func (i *Interpreter) execute(stmt parser.Stmt) error {
+	if err := i.step(); err != nil {
+		return err
+	}
+	if err := i.visit(stmt); err != nil {
+		return err
+	}
 	switch s := stmt.(type) {
 	case parser.Print:
 		val, err := i.evaluate(s.Inner)
 		if err != nil {
 			return err
 		}
-		fmt.Println(val.String())
+		fmt.Fprintln(i.stdout, val.String())
 	case parser.Expression:
 		_, err := i.evaluate(s.Inner)
 		if err != nil {
 			return err
 		}
 	case parser.Var:
-		var value types.ClavType
+		var value types.ClavType = types.Nil{}
 		var err error
 		if s.Initializer != nil {
 			value, err = i.evaluate(s.Initializer)
@@ -50,41 +139,102 @@ func (i *Interpreter) execute(stmt parser.Stmt) error {
 		}
 		i.environment.Define(s.Name.Lexeme, value)
 	case parser.Block:
-		i.executeBlock(s.Statements)
+		return i.executeBlock(s.Statements)
+	case parser.Try:
+		return i.executeTry(s)
+	case parser.Import:
+		return i.executeImport(s)
+	case parser.Function:
+		fn := &Function{declaration: s, closure: i.environment.Capture(), interpreter: i, file: i.currentFile()}
+		i.environment.Define(s.Name.Lexeme, fn)
+	case parser.Return:
+		var value types.ClavType = types.Nil{}
+		if s.Value != nil {
+			var err error
+			if value, err = i.evaluate(s.Value); err != nil {
+				return err
+			}
+		}
+		return returnSignal{keyword: s.Keyword, value: value}
 	}
 	return nil
 }
 
+func (i *Interpreter) executeTry(stmt parser.Try) error {
+	err := i.executeBlock(stmt.Body)
+	var runtimeErr InterpreterError
+	if !errors.As(err, &runtimeErr) {
+		if i.coverage != nil && (err == nil || errors.As(err, new(returnSignal))) {
+			i.coverage.Branch(i.frames[len(i.frames)-1].File, stmt.Keyword, coverage.TryCompleted)
+		}
+		// Either no error at all or one, like a limit, that must not be caught.
+		return err
+	}
+	if i.coverage != nil {
+		i.coverage.Branch(i.frames[len(i.frames)-1].File, stmt.Keyword, coverage.TryCaught)
+	}
+	i.environment.NewScope()
+	defer i.environment.EndScope()
+	scope := "catch " + stmt.Name.Lexeme
+	i.pushScope(scope)
+	defer i.popScope(scope)
+	i.environment.Define(stmt.Name.Lexeme, types.String{Value: runtimeErr.message})
+	return i.executeBlock(stmt.Handler)
+}
+
 func (i *Interpreter) executeBlock(statements []parser.Stmt) error {
 	i.environment.NewScope()
+	defer i.environment.EndScope()
+	i.pushScope("block")
+	defer i.popScope("block")
+	return i.executeStatements(statements)
+}
+
+// executeStatements runs statements in the current scope.
+func (i *Interpreter) executeStatements(statements []parser.Stmt) error {
 	for _, stmt := range statements {
 		if err := i.execute(stmt); err != nil {
-			i.environment.EndScope()
-			return err
+			return i.locate(err)
 		}
 	}
-	i.environment.EndScope()
 	return nil
 }
 
 func (i *Interpreter) evaluate(expr parser.Expr) (types.ClavType, error) {
+	if err := i.step(); err != nil {
+		return nil, err
+	}
+	value, err := i.evalutateExpr(expr)
+	if err == nil && i.tracer != nil {
+		i.tracer.Expression(i, expr, value)
+	}
+	return value, err
+}
+
+func (i *Interpreter) evalutateExpr(expr parser.Expr) (types.ClavType, error) {
 	switch e := expr.(type) {
 	case parser.Literal:
 		return i.evalutateLiteral(e), nil
 	case parser.Grouping:
 		return i.evalutateGrouping(e)
 	case parser.Unary:
-		return i.evalutateUnary(e)
+		return i.track(i.evalutateUnary(e))
 	case parser.Binary:
-		return i.evalutateBinary(e)
+		return i.track(i.evalutateBinary(e))
 	case parser.Variable:
-		return i.environment.Get(e.Name)
+		return i.evalutateVariable(e.Name)
 	case parser.Assign:
 		value, err := i.evaluate(e.Value)
 		if err != nil {
 			return nil, err
 		}
 		return i.evalutateAssign(e.Name, value)
+	case parser.Call:
+		return i.track(i.evalutateCall(e))
+	case parser.Get:
+		return i.evalutateGet(e)
+	case parser.ListLiteral:
+		return i.track(i.evalutateList(e))
 	}
 	panic("Unreachable")
 }
@@ -104,10 +254,16 @@ func (i *Interpreter) evalutateUnary(expr parser.Unary) (types.ClavType, error)
 	}
 	switch expr.Operator.Type {
 	case token.Bang:
-		old := right.(types.Boolean)
+		old, ok := right.(types.Boolean)
+		if !ok {
+			return nil, newInterpreterError("Cannot invert non-boolean type "+typeName(right), expr.Operator)
+		}
 		return types.Boolean{Value: !old.Value}, nil
 	case token.Minus:
-		old := right.(types.Number)
+		old, ok := right.(types.Number)
+		if !ok {
+			return nil, newInterpreterError("Cannot negate non-numeric type "+typeName(right), expr.Operator)
+		}
 		return types.Number{Value: -old.Value}, nil
 	}
 	panic("Unreachable")
@@ -191,19 +347,97 @@ func (i *Interpreter) evalutateBinary(expr parser.Binary) (types.ClavType, error
 	return nil, nil
 }
 
-func (i *Interpreter) evalutateAssign(name token.Token, value types.ClavType) (types.ClavType, error) {
-	if v, _ := i.environment.Get(name); v != nil {
-		i.environment.Assign(name, value)
-		return value, nil
+func (i *Interpreter) evalutateCall(expr parser.Call) (types.ClavType, error) {
+	callee, err := i.evaluate(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]types.ClavType, 0, len(expr.Arguments))
+	for _, arg := range expr.Arguments {
+		value, err := i.evaluate(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	fn, ok := callee.(types.Callable)
+	if !ok {
+		return nil, newInterpreterError("Can only call functions", expr.Paren)
+	}
+	if fn.Arity() != types.Variadic && fn.Arity() != len(args) {
+		message := fmt.Sprintf("Expected %d arguments but got %d", fn.Arity(), len(args))
+		return nil, newInterpreterError(message, expr.Paren)
+	}
+	value, err := fn.Call(args)
+	if err != nil {
+		return nil, callError(err, expr.Paren)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) evalutateGet(expr parser.Get) (types.ClavType, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
 	}
+	name := expr.Name.Lexeme
+	switch o := object.(type) {
+	case types.Module:
+		if member, ok := o.Members[name]; ok {
+			return member, nil
+		}
+		return nil, newInterpreterError("Undefined property '"+name+"' on "+o.String(), expr.Name)
+	case types.String:
+		if method, ok := i.stringMethod(o.Value, name); ok {
+			return method, nil
+		}
+	case *types.List:
+		if method, ok := i.listMethod(o, name); ok {
+			return method, nil
+		}
+	case *types.Map:
+		if method, ok := i.mapMethod(o, name); ok {
+			return method, nil
+		}
+	case types.Object:
+		if property, ok := o.Property(name); ok {
+			return property, nil
+		}
+	}
+	return nil, newInterpreterError("Undefined property '"+name+"' on "+typeName(object), expr.Name)
+}
+
+func (i *Interpreter) evalutateList(expr parser.ListLiteral) (types.ClavType, error) {
+	elements := make([]types.ClavType, 0, len(expr.Elements))
+	for _, e := range expr.Elements {
+		value, err := i.evaluate(e)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+	return types.NewList(elements...), nil
+}
+
+func (i *Interpreter) evalutateVariable(name token.Token) (types.ClavType, error) {
+	value, err := i.environment.Get(name)
+	if err != nil {
+		return nil, newInterpreterError("Undefined variable '"+name.Lexeme+"'", name)
+	}
+	return value, nil
+}
 
-	return nil, newInterpreterError("Undefined variable '"+name.Lexeme+"'", name)
+func (i *Interpreter) evalutateAssign(name token.Token, value types.ClavType) (types.ClavType, error) {
+	if _, err := i.environment.Assign(name, value); err != nil {
+		return nil, newInterpreterError("Undefined variable '"+name.Lexeme+"'", name)
+	}
+	return value, nil
 }
 
-func numeric(args ...any) (bool, string) {
+func numeric(args ...types.ClavType) (bool, string) {
 	for _, a := range args {
 		if _, ok := a.(types.Number); !ok {
-			return false, reflect.TypeOf(a).Name()
+			return false, typeName(a)
 		}
 	}
 	return true, ""
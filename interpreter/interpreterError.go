@@ -6,9 +6,13 @@ import (
 	"github.com/it-a-me/clavlang/token"
 )
 
+// InterpreterError is a runtime error raised by a clav program. Unlike the
+// limit errors it can be caught with try/catch.
 type InterpreterError struct {
 	message string
 	token   token.Token
+	file    string
+	cause   error
 }
 
 func newInterpreterError(message string, token token.Token) InterpreterError {
@@ -20,3 +24,43 @@ func newInterpreterError(message string, token token.Token) InterpreterError {
 func (i InterpreterError) Error() string {
 	return fmt.Sprintf("Error on line %d: %s", i.token.Line, i.message)
 }
+
+// Line is the source line the error was raised on.
+func (i InterpreterError) Line() int {
+	return i.token.Line
+}
+
+// File is the absolute path of the file the error was raised in, or empty
+// when it is not known.
+func (i InterpreterError) File() string {
+	return i.file
+}
+
+// Message is the error without its location.
+func (i InterpreterError) Message() string {
+	return i.message
+}
+
+func (i InterpreterError) Unwrap() error {
+	return i.cause
+}
+
+// callError attaches the location of a call to a plain error returned by a
+// native function. Errors that already carry context are passed through.
+func callError(err error, paren token.Token) error {
+	switch err.(type) {
+	case InterpreterError, StepLimitError, DepthLimitError, MemoryLimitError, CanceledError, ExitError, DebugError:
+		return err
+	}
+	return InterpreterError{message: err.Error(), token: paren, cause: err}
+}
+
+// locate records the file of the innermost frame on a runtime error that
+// does not know where it was raised yet.
+func (i *Interpreter) locate(err error) error {
+	if e, ok := err.(InterpreterError); ok && e.file == "" {
+		e.file = i.frames[len(i.frames)-1].File
+		return e
+	}
+	return err
+}
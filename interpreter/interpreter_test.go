@@ -0,0 +1,34 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+func run(t *testing.T, source string, opts ...interpreter.Option) (string, error) {
+	t.Helper()
+	stmts := parse(t, source)
+	var out strings.Builder
+	opts = append(opts, interpreter.WithStdout(&out))
+	err := interpreter.NewInterpreter(opts...).Interpret(stmts)
+	return out.String(), err
+}
+
+func parse(t *testing.T, source string) []parser.Stmt {
+	t.Helper()
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatalf("scan %q: %v", source, errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatalf("parse %q: %v", source, errs)
+	}
+	return stmts
+}
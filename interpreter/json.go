@@ -0,0 +1,202 @@
+package interpreter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+func jsonModule() types.Module {
+	return types.Module{Name: "json", Members: map[string]types.ClavType{
+		"parse":     native("json.parse", 1, jsonParse),
+		"stringify": native("json.stringify", types.Variadic, jsonStringify),
+	}}
+}
+
+func jsonParse(args []types.ClavType) (types.ClavType, error) {
+	text, err := stringArg("json.parse", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return nil, errors.New("json.parse: " + err.Error())
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("json.parse: unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+// decodeJSON reads the next value from decoder. Objects are decoded token by
+// token so that maps keep the key order of the document.
+func decodeJSON(decoder *json.Decoder) (types.ClavType, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := types.NewList()
+			for decoder.More() {
+				element, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list.Elements = append(list.Elements, element)
+			}
+			_, err := decoder.Token()
+			return list, err
+		}
+		m := types.NewMap()
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return nil, errors.New("object key must be a string")
+			}
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(key, value)
+		}
+		_, err := decoder.Token()
+		return m, err
+	case json.Number:
+		f, err := t.Float64()
+		return types.Number{Value: f}, err
+	case string:
+		return types.String{Value: t}, nil
+	case bool:
+		return types.Boolean{Value: t}, nil
+	}
+	return types.Nil{}, nil
+}
+
+// jsonStringify encodes its first argument. An optional second argument is
+// the indent, either a number of spaces or a string.
+func jsonStringify(args []types.ClavType) (types.ClavType, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("json.stringify: expected 1 or 2 arguments")
+	}
+	indent := ""
+	if len(args) == 2 {
+		switch v := args[1].(type) {
+		case types.String:
+			indent = v.Value
+		case types.Number:
+			spaces, err := intArg("json.stringify", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			indent = strings.Repeat(" ", max(spaces, 0))
+		default:
+			return nil, argError("json.stringify", 1, "a Number or String", v)
+		}
+	}
+	e := jsonEncoder{indent: indent, seen: map[types.ClavType]bool{}}
+	if err := e.encode(args[0], 0); err != nil {
+		return nil, errors.New("json.stringify: " + err.Error())
+	}
+	return types.String{Value: e.buf.String()}, nil
+}
+
+type jsonEncoder struct {
+	buf    bytes.Buffer
+	indent string
+	// seen holds the lists and maps currently being encoded, to detect cycles.
+	seen map[types.ClavType]bool
+}
+
+func (e *jsonEncoder) encode(value types.ClavType, depth int) error {
+	switch v := value.(type) {
+	case nil, types.Nil:
+		e.buf.WriteString("null")
+	case types.Boolean:
+		return e.literal(v.Value)
+	case types.String:
+		return e.literal(v.Value)
+	case types.Number:
+		if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+			return errors.New("cannot serialize " + v.String())
+		}
+		return e.literal(v.Value)
+	case *types.List:
+		return e.container(v, depth, '[', ']', len(v.Elements), func(j int) error {
+			return e.encode(v.Elements[j], depth+1)
+		})
+	case *types.Map:
+		keys := v.Keys()
+		return e.container(v, depth, '{', '}', len(keys), func(j int) error {
+			if err := e.literal(keys[j]); err != nil {
+				return err
+			}
+			e.buf.WriteByte(':')
+			if e.indent != "" {
+				e.buf.WriteByte(' ')
+			}
+			element, _ := v.Get(keys[j])
+			return e.encode(element, depth+1)
+		})
+	default:
+		return errors.New("cannot serialize value of type " + typeName(value))
+	}
+	return nil
+}
+
+// literal writes a scalar using encoding/json without HTML escaping.
+func (e *jsonEncoder) literal(scalar any) error {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(scalar); err != nil {
+		return err
+	}
+	e.buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return nil
+}
+
+func (e *jsonEncoder) container(
+	value types.ClavType, depth int, open, end byte, length int, element func(int) error,
+) error {
+	if e.seen[value] {
+		return errors.New("cannot serialize cyclic structure")
+	}
+	e.seen[value] = true
+	defer delete(e.seen, value)
+	e.buf.WriteByte(open)
+	for j := range length {
+		if j > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := element(j); err != nil {
+			return err
+		}
+	}
+	if length > 0 {
+		e.newline(depth)
+	}
+	e.buf.WriteByte(end)
+	return nil
+}
+
+func (e *jsonEncoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(strings.Repeat(e.indent, depth))
+}
@@ -0,0 +1,107 @@
+package interpreter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	documents := []string{
+		`null`,
+		`true`,
+		`-12.5`,
+		`1e+21`,
+		`"tab\tquote\" <html> é"`,
+		`[]`,
+		`{}`,
+		`[1,"two",false,null,[3]]`,
+		`{"z":1,"a":{"nested":[1,2,{"k":"v"}]},"m":"x"}`,
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.json")
+	opts := []interpreter.Option{
+		interpreter.WithScriptDir(dir),
+		interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{dir}}),
+	}
+	for _, doc := range documents {
+		if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		out, err := run(t, `print json.stringify(json.parse(fs.readFile("doc.json")));`, opts...)
+		if err != nil {
+			t.Errorf("%s: %v", doc, err)
+			continue
+		}
+		if got := strings.TrimSuffix(out, "\n"); got != doc {
+			t.Errorf("round trip of %s produced %s", doc, got)
+		}
+	}
+}
+
+func TestJSONValueRoundTrip(t *testing.T) {
+	source := `
+var value = json.parse("{}");
+value.set("list", [1, 2.5, "three", true, nil]);
+value.set("empty", []);
+var text = json.stringify(value);
+print text;
+print json.stringify(json.parse(text)) == text;
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"list":[1,2.5,"three",true,null],"empty":[]}` + "\ntrue\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestJSONIndent(t *testing.T) {
+	source := `
+var inner = json.parse("{}");
+inner.set("n", 1);
+var m = json.parse("{}");
+m.set("k", ["a", inner, []]);
+m.set("empty", json.parse("{}"));
+print json.stringify(m, 2);
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "k": [
+    "a",
+    {
+      "n": 1
+    },
+    []
+  ],
+  "empty": {}
+}
+`
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	sources := map[string]string{
+		"function": `json.stringify(math.floor);`,
+		"module":   `json.stringify(math);`,
+		"cycle":    `var l = []; l.push([l]); json.stringify(l);`,
+		"nan":      `json.stringify(math.nan);`,
+		"syntax":   `json.parse("[1,");`,
+		"trailing": `json.parse("1 2");`,
+	}
+	for name, source := range sources {
+		if _, err := run(t, source); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultMaxDepth is the depth limit used when Limits.MaxDepth is zero. It
+// keeps runaway recursion from overflowing the Go stack.
+const DefaultMaxDepth = 10000
+
+// Unlimited disables a limit, including the depth limit, which is otherwise
+// on by default.
+const Unlimited = -1
+
+// Limits bounds the work a single Interpreter may perform. A zero value
+// field means the corresponding limit is disabled, except for MaxDepth.
+type Limits struct {
+	// MaxSteps is the number of statements and expressions that may be
+	// evaluated over the lifetime of the Interpreter.
+	MaxSteps int
+	// MaxDepth is how deeply function calls and imports may nest, which
+	// bounds recursion. Zero means DefaultMaxDepth and Unlimited lifts it.
+	MaxDepth int
+	// MaxMemory is the approximate number of bytes of values a script may
+	// create over the lifetime of the Interpreter. Like MaxSteps it is a
+	// budget: values the script no longer uses are not given back, so a
+	// long-running script can exhaust it while holding little at once. See
+	// Interpreter.Allocated.
+	MaxMemory int
+}
+
+type Option func(*Interpreter)
+
+// WithLimits applies the given execution limits to the Interpreter.
+func WithLimits(limits Limits) Option {
+	return func(i *Interpreter) {
+		i.limits = limits
+	}
+}
+
+// StepLimitError is returned once the step budget in Limits.MaxSteps is spent.
+type StepLimitError struct {
+	Limit int
+}
+
+func (e StepLimitError) Error() string {
+	return fmt.Sprintf("Execution aborted: step budget of %d exhausted", e.Limit)
+}
+
+// DepthLimitError is returned when calls nest deeper than Limits.MaxDepth.
+type DepthLimitError struct {
+	Limit int
+}
+
+func (e DepthLimitError) Error() string {
+	return fmt.Sprintf("Execution aborted: maximum depth of %d exceeded", e.Limit)
+}
+
+// CanceledError is returned when the context passed to InterpretContext is
+// done. It unwraps to the context's error.
+type CanceledError struct {
+	Err error
+}
+
+func (e CanceledError) Error() string {
+	return "Execution aborted: " + e.Err.Error()
+}
+
+func (e CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// step accounts for one statement or expression being evaluated and checks
+// the step budget and the context.
+func (i *Interpreter) step() error {
+	if i.ctx != nil {
+		select {
+		case <-i.ctx.Done():
+			return CanceledError{Err: context.Cause(i.ctx)}
+		default:
+		}
+	}
+	if i.limits.MaxSteps > 0 && i.steps >= i.limits.MaxSteps {
+		return StepLimitError{Limit: i.limits.MaxSteps}
+	}
+	i.steps++
+	return nil
+}
+
+// checkDepth fails when one more call would nest deeper than the depth
+// limit. The main script is not a call.
+func (i *Interpreter) checkDepth() error {
+	limit := i.limits.MaxDepth
+	if limit == 0 {
+		limit = DefaultMaxDepth
+	}
+	if limit > 0 && len(i.frames)-1 >= limit {
+		return DepthLimitError{Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package interpreter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestStepLimit(t *testing.T) {
+	source := `var a = 1; var b = 2; var c = 3;`
+	_, err := run(t, source, interpreter.WithLimits(interpreter.Limits{MaxSteps: 4}))
+	var limit interpreter.StepLimitError
+	if !errors.As(err, &limit) || limit.Limit != 4 {
+		t.Fatalf("got error %v, want a StepLimitError", err)
+	}
+
+	// Every statement and expression is a step.
+	if _, err := run(t, `var a = 1;`, interpreter.WithLimits(interpreter.Limits{MaxSteps: 2})); err != nil {
+		t.Errorf("two steps: %v", err)
+	}
+	if _, err := run(t, `var a = 1;`, interpreter.WithLimits(interpreter.Limits{MaxSteps: 1})); !errors.As(err, new(interpreter.StepLimitError)) {
+		t.Errorf("one step: got %v", err)
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	source := `fun forever(n) { return forever(n + 1); } forever(0);`
+	_, err := run(t, source, interpreter.WithLimits(interpreter.Limits{MaxDepth: 50}))
+	var limit interpreter.DepthLimitError
+	if !errors.As(err, &limit) || limit.Limit != 50 {
+		t.Fatalf("got error %v, want a DepthLimitError", err)
+	}
+
+	// A zero MaxDepth still stops runaway recursion.
+	_, err = run(t, source, interpreter.WithLimits(interpreter.Limits{MaxSteps: 1000000}))
+	if !errors.As(err, &limit) || limit.Limit != interpreter.DefaultMaxDepth {
+		t.Errorf("got error %v, want the default DepthLimitError", err)
+	}
+
+	// Unlimited lifts it, leaving the step budget to end the recursion well
+	// past the default depth.
+	_, err = run(t, source, interpreter.WithLimits(interpreter.Limits{MaxSteps: 200000, MaxDepth: interpreter.Unlimited}))
+	if !errors.As(err, new(interpreter.StepLimitError)) {
+		t.Errorf("got error %v, want a StepLimitError", err)
+	}
+
+	// Only calls count, not how deeply statements and expressions nest.
+	nested := `fun f() { { return ` + strings.Repeat("(", 60) + `1` + strings.Repeat(")", 60) + `; } } print f();`
+	out, err := run(t, nested, interpreter.WithLimits(interpreter.Limits{MaxDepth: 1}))
+	if err != nil || out != "1\n" {
+		t.Errorf("got %q, %v, want one call to be within a depth of 1", out, err)
+	}
+	if _, err := run(t, `fun g() {} fun f() { g(); } f();`, interpreter.WithLimits(interpreter.Limits{MaxDepth: 1})); !errors.As(err, &limit) {
+		t.Errorf("got error %v, want a DepthLimitError for a nested call", err)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	stmts := parse(t, `var ran = true;`)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("shutting down")
+	cancel(cause)
+
+	i := interpreter.NewInterpreter()
+	err := i.InterpretContext(ctx, stmts)
+	var canceled interpreter.CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("got error %v, want a CanceledError", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("%v does not unwrap to the cause of the cancellation", err)
+	}
+
+	// The context only applies to the call it was passed to.
+	if err := i.Interpret(stmts); err != nil {
+		t.Errorf("Interpret: %v", err)
+	}
+}
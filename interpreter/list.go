@@ -0,0 +1,55 @@
+package interpreter
+
+import (
+	"errors"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// listMethod binds the method called name to the list l.
+func (i *Interpreter) listMethod(l *types.List, name string) (types.NativeFunction, bool) {
+	fn := "list." + name
+	switch name {
+	case "length":
+		return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+			return types.Number{Value: float64(len(l.Elements))}, nil
+		}), true
+	case "get":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			index, err := listIndex(fn, l, args)
+			if err != nil {
+				return nil, err
+			}
+			return l.Elements[index], nil
+		}), true
+	case "set":
+		return native(fn, 2, func(args []types.ClavType) (types.ClavType, error) {
+			index, err := listIndex(fn, l, args)
+			if err != nil {
+				return nil, err
+			}
+			l.Elements[index] = args[1]
+			return args[1], nil
+		}), true
+	case "push":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			if err := i.charge(valueOverhead); err != nil {
+				return nil, err
+			}
+			l.Elements = append(l.Elements, args[0])
+			return types.Nil{}, nil
+		}), true
+	}
+	return types.NativeFunction{}, false
+}
+
+func listIndex(fn string, l *types.List, args []types.ClavType) (int, error) {
+	index, err := intArg(fn, args, 0)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(l.Elements) {
+		return 0, errors.New(fn + ": index out of range")
+	}
+	return index, nil
+}
@@ -0,0 +1,40 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLists(t *testing.T) {
+	source := `
+print [];
+print ["a", true, [1]];
+print [1 + 1, "x" + "y"];
+var l = [10, 20];
+print l.length();
+print l.get(1);
+l.push(30);
+l.set(0, "first");
+print l;
+var alias = l;
+alias.push(40);
+print l.length();
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[]\n[a, true, [1]]\n[2, xy]\n2\n20\n[first, 20, 30]\n4\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	for source, want := range map[string]string{
+		`[1, 2].get(0.5);`: "list.get: argument 1 must be an integer, got Number",
+		`[1].get(1);`:      "list.get: index out of range",
+		`[1].set(-1, 2);`:  "list.set: index out of range",
+	} {
+		_, err := run(t, source)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", source, err, want)
+		}
+	}
+}
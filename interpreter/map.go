@@ -0,0 +1,63 @@
+package interpreter
+
+import (
+	"github.com/it-a-me/clavlang/types"
+)
+
+// mapMethod binds the method called name to the map m.
+func (i *Interpreter) mapMethod(m *types.Map, name string) (types.NativeFunction, bool) {
+	fn := "map." + name
+	switch name {
+	case "length":
+		return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+			return types.Number{Value: float64(m.Len())}, nil
+		}), true
+	case "get":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			key, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			if value, ok := m.Get(key); ok {
+				return value, nil
+			}
+			return types.Nil{}, nil
+		}), true
+	case "has":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			key, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			_, ok := m.Get(key)
+			return types.Boolean{Value: ok}, nil
+		}), true
+	case "set":
+		return native(fn, 2, func(args []types.ClavType) (types.ClavType, error) {
+			key, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m.Get(key); !ok {
+				if err := i.charge(2 * valueOverhead); err != nil {
+					return nil, err
+				}
+			}
+			m.Set(key, args[1])
+			return args[1], nil
+		}), true
+	case "remove":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			key, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			return types.Boolean{Value: m.Delete(key)}, nil
+		}), true
+	case "keys":
+		return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+			return stringList(m.Keys()), nil
+		}), true
+	}
+	return types.NativeFunction{}, false
+}
@@ -0,0 +1,38 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestMaps(t *testing.T) {
+	dir := t.TempDir()
+	caps := interpreter.WithCapabilities(interpreter.Capabilities{ReadRoots: []string{dir}})
+	source := `
+var m = fs.stat(".");
+m.remove("modified");
+print m.keys();
+print m.length();
+m.set("name", "renamed");
+m.set("extra", 1);
+print m.get("name");
+print m.get("missing");
+print m.has("extra");
+print m.remove("size");
+print m.remove("size");
+print m;
+`
+	out, err := run(t, source, caps, interpreter.WithScriptDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[name, size, isDir]\n3\nrenamed\nnil\ntrue\ntrue\nfalse\n{name: renamed, isDir: true, extra: 1}\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if _, err := run(t, `fs.stat(".").get(1);`, caps, interpreter.WithScriptDir(dir)); err == nil || !strings.Contains(err.Error(), "map.get") {
+		t.Errorf("non-string key: got error %v", err)
+	}
+}
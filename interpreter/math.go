@@ -0,0 +1,113 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+func mathModule() types.Module {
+	members := map[string]types.ClavType{
+		"pi":    types.Number{Value: math.Pi},
+		"e":     types.Number{Value: math.E},
+		"inf":   types.Number{Value: math.Inf(1)},
+		"nan":   types.Number{Value: math.NaN()},
+		"pow":   binaryMath("math.pow", math.Pow),
+		"min":   foldMath("math.min", math.Min),
+		"max":   foldMath("math.max", math.Max),
+		"clamp": native("math.clamp", 3, mathClamp),
+		"isNaN": predicateMath("math.isNaN", math.IsNaN),
+		"isInf": predicateMath("math.isInf", func(x float64) bool { return math.IsInf(x, 0) }),
+		"isFinite": predicateMath("math.isFinite", func(x float64) bool {
+			return !math.IsNaN(x) && !math.IsInf(x, 0)
+		}),
+	}
+	unary := map[string]func(float64) float64{
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+		"abs":   math.Abs,
+		"sqrt":  math.Sqrt,
+		"log":   math.Log,
+		"exp":   math.Exp,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+	}
+	for name, fn := range unary {
+		members[name] = unaryMath("math."+name, fn)
+	}
+	return types.Module{Name: "math", Members: members}
+}
+
+func unaryMath(name string, fn func(float64) float64) types.NativeFunction {
+	return native(name, 1, func(args []types.ClavType) (types.ClavType, error) {
+		x, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return types.Number{Value: fn(x)}, nil
+	})
+}
+
+func binaryMath(name string, fn func(float64, float64) float64) types.NativeFunction {
+	return native(name, 2, func(args []types.ClavType) (types.ClavType, error) {
+		x, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		y, err := numberArg(name, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return types.Number{Value: fn(x, y)}, nil
+	})
+}
+
+// foldMath reduces one or more numeric arguments with fn.
+func foldMath(name string, fn func(float64, float64) float64) types.NativeFunction {
+	return native(name, types.Variadic, func(args []types.ClavType) (types.ClavType, error) {
+		if len(args) == 0 {
+			return nil, errors.New(name + ": expected at least 1 argument")
+		}
+		acc, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		for j := 1; j < len(args); j++ {
+			x, err := numberArg(name, args, j)
+			if err != nil {
+				return nil, err
+			}
+			acc = fn(acc, x)
+		}
+		return types.Number{Value: acc}, nil
+	})
+}
+
+func predicateMath(name string, fn func(float64) bool) types.NativeFunction {
+	return native(name, 1, func(args []types.ClavType) (types.ClavType, error) {
+		x, err := numberArg(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return types.Boolean{Value: fn(x)}, nil
+	})
+}
+
+func mathClamp(args []types.ClavType) (types.ClavType, error) {
+	var bounds [3]float64
+	for j := range bounds {
+		x, err := numberArg("math.clamp", args, j)
+		if err != nil {
+			return nil, err
+		}
+		bounds[j] = x
+	}
+	x, lo, hi := bounds[0], bounds[1], bounds[2]
+	if lo > hi {
+		return nil, errors.New("math.clamp: lower bound is greater than upper bound")
+	}
+	return types.Number{Value: math.Max(lo, math.Min(x, hi))}, nil
+}
@@ -0,0 +1,56 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	source := `
+print math.floor(3.7);
+print math.ceil(3.2);
+print math.round(2.5);
+print math.abs(-4);
+print math.sqrt(81);
+print math.pow(2, 10);
+print math.exp(0);
+print math.log(1);
+print math.sin(0);
+print math.cos(0);
+print math.min(3, 1, 2);
+print math.max(3, 1, 2);
+print math.clamp(15, 0, 10);
+print math.clamp(-1, 0, 10);
+print math.pi;
+print math.inf;
+print math.isNaN(math.nan);
+print math.isInf(math.inf);
+print math.isFinite(math.inf);
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "3\n4\n3\n4\n9\n1024\n1\n0\n0\n1\n1\n3\n10\n0\n3.141592653589793\n+Inf\ntrue\ntrue\nfalse\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	for source, want := range map[string]string{
+		`math.floor("3");`:       "math.floor: argument 1 must be a Number, got String",
+		`math.pow(2);`:           "Expected 2 arguments but got 1",
+		`math.min();`:            "math.min: expected at least 1 argument",
+		`math.max(1, nil);`:      "math.max: argument 2 must be a Number, got Nil",
+		`math.clamp(1, 5, 0);`:   "math.clamp: lower bound is greater than upper bound",
+		`var x = 1; x();`:        "Can only call functions",
+		`math.floor(1)(2);`:      "Can only call functions",
+		`print math.missing(1);`: "Undefined property 'missing'",
+	} {
+		_, err := run(t, source)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", source, err, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// valueOverhead approximates the bytes needed to hold any clav value: an
+// interface header plus the boxed value itself.
+const valueOverhead = 16
+
+// MemoryLimitError is returned once the values created by a script, live
+// or not, add up to more than Limits.MaxMemory bytes.
+type MemoryLimitError struct {
+	Limit int
+}
+
+func (e MemoryLimitError) Error() string {
+	return fmt.Sprintf("Execution aborted: memory limit of %d bytes exceeded", e.Limit)
+}
+
+// Allocated reports the approximate number of bytes of clav values the
+// Interpreter has created so far. It only grows: values are counted when
+// they are created and never again once unused.
+func (i *Interpreter) Allocated() int {
+	return i.allocated
+}
+
+// track accounts for a freshly created value. It has the shape of the
+// evaluate helpers so their results can be passed straight through.
+func (i *Interpreter) track(value types.ClavType, err error) (types.ClavType, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := i.charge(sizeOf(value)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// charge accounts for bytes that were just allocated.
+func (i *Interpreter) charge(bytes int) error {
+	i.allocated += bytes
+	if i.profiler != nil {
+		i.profiler.Allocate(i, bytes)
+	}
+	if i.limits.MaxMemory > 0 && i.allocated > i.limits.MaxMemory {
+		return MemoryLimitError{Limit: i.limits.MaxMemory}
+	}
+	return nil
+}
+
+// reserve checks that bytes could be allocated without exceeding the memory
+// limit. Natives call it before building large values so that the host is
+// never asked for the memory in the first place.
+func (i *Interpreter) reserve(bytes int) error {
+	if i.limits.MaxMemory > 0 && (bytes < 0 || i.allocated+bytes > i.limits.MaxMemory) {
+		return MemoryLimitError{Limit: i.limits.MaxMemory}
+	}
+	return nil
+}
+
+// memoryLeft reports how many more bytes may be allocated, or false when
+// memory is not limited.
+func (i *Interpreter) memoryLeft() (int, bool) {
+	if i.limits.MaxMemory <= 0 {
+		return 0, false
+	}
+	return max(i.limits.MaxMemory-i.allocated, 0), true
+}
+
+// sizeOf approximates the memory held by value. Accounting is shallow:
+// values that are shared rather than copied are charged when first created.
+func sizeOf(value types.ClavType) int {
+	switch v := value.(type) {
+	case types.String:
+		return valueOverhead + len(v.Value)
+	case *types.List:
+		return valueOverhead + len(v.Elements)*valueOverhead
+	case *types.Map:
+		return valueOverhead + v.Len()*2*valueOverhead
+	}
+	return valueOverhead
+}
@@ -0,0 +1,62 @@
+package interpreter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	limits := interpreter.WithLimits(interpreter.Limits{MaxMemory: 1 << 16})
+	// Each doubling of the string allocates a new one twice the size.
+	source := `var s = "0123456789abcdef";` + strings.Repeat(` s = s + s;`, 13)
+	_, err := run(t, source, limits)
+	var limit interpreter.MemoryLimitError
+	if !errors.As(err, &limit) || limit.Limit != 1<<16 {
+		t.Errorf("got error %v, want a MemoryLimitError", err)
+	}
+	// Methods that build strings are charged before they allocate.
+	if _, err := run(t, `"ab".repeat(1000000);`, limits); !errors.As(err, &limit) {
+		t.Errorf("repeat: got error %v, want a MemoryLimitError", err)
+	}
+	for _, source := range []string{
+		`"ab".repeat(1000).replace("a", "x".repeat(100));`,
+		`"x".repeat(30000).join(["a", "b", "c", "d"]);`,
+	} {
+		i := interpreter.NewInterpreter(limits)
+		if err := i.Interpret(parse(t, source)); !errors.As(err, &limit) {
+			t.Errorf("%s: got error %v, want a MemoryLimitError", source, err)
+		}
+		if i.Allocated() > 1<<16 {
+			t.Errorf("%s: %d bytes were allocated past the limit", source, i.Allocated())
+		}
+	}
+	if _, err := run(t, `var s = "0123456789abcdef"; s = s + s;`, limits); err != nil {
+		t.Errorf("within the limit: %v", err)
+	}
+}
+
+// TestMemoryBudget checks that the limit counts every value created, even
+// ones that are no longer reachable.
+func TestMemoryBudget(t *testing.T) {
+	i := interpreter.NewInterpreter(interpreter.WithLimits(interpreter.Limits{MaxMemory: 1000}))
+	stmts := parse(t, `var s = "0123456789" + "0123456789"; s = nil;`)
+	var err error
+	runs := 0
+	for err == nil && runs < 100 {
+		before := i.Allocated()
+		err = i.Interpret(stmts)
+		if err == nil && i.Allocated() <= before {
+			t.Fatalf("Allocated went from %d to %d", before, i.Allocated())
+		}
+		runs++
+	}
+	if !errors.As(err, new(interpreter.MemoryLimitError)) {
+		t.Fatalf("got error %v after %d runs, want a MemoryLimitError", err, runs)
+	}
+	if runs < 2 {
+		t.Errorf("the limit was hit on the first run")
+	}
+}
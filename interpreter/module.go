@@ -0,0 +1,217 @@
+package interpreter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// WithScriptPath sets the file being run. Imports and the fs module resolve
+// relative paths against its directory.
+func WithScriptPath(path string) Option {
+	return func(i *Interpreter) {
+		i.scriptDir = filepath.Dir(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			i.importing = []string{abs}
+		}
+	}
+}
+
+// WithSearchPath adds directories that imports are resolved against when
+// the path is not found relative to the importing file.
+func WithSearchPath(dirs ...string) Option {
+	return func(i *Interpreter) {
+		i.searchPath = append(i.searchPath, dirs...)
+	}
+}
+
+// WithPackages maps package names to directories. An import whose first
+// path element names a package is resolved inside that package's directory.
+func WithPackages(packages map[string]string) Option {
+	return func(i *Interpreter) {
+		i.packages = packages
+	}
+}
+
+func (i *Interpreter) executeImport(stmt parser.Import) error {
+	path, err := i.resolveImport(stmt.Path)
+	if err != nil {
+		return err
+	}
+	module, err := i.loadModule(path, stmt.Path)
+	if err != nil {
+		return err
+	}
+	if len(stmt.Names) == 0 {
+		name := stmt.Alias.Lexeme
+		if name == "" {
+			name = module.Name
+		}
+		i.environment.Define(name, module)
+		return nil
+	}
+	for _, name := range stmt.Names {
+		member, ok := module.Members[name.Lexeme]
+		if !ok {
+			return newInterpreterError("Module '"+module.Name+"' has no exported name '"+name.Lexeme+"'", name)
+		}
+		i.environment.Define(name.Lexeme, member)
+	}
+	return nil
+}
+
+// resolveImport finds the file an import refers to, first relative to the
+// importing file, then in packages and then along the search path.
+func (i *Interpreter) resolveImport(path token.Token) (string, error) {
+	name := path.Literal.String()
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = []string{filepath.Join(i.scriptDir, name)}
+		first, rest, _ := strings.Cut(filepath.ToSlash(name), "/")
+		if dir, ok := i.packages[first]; ok {
+			candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(rest)))
+		}
+		for _, dir := range i.searchPath {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(candidate)
+		if err != nil {
+			return "", newInterpreterError(err.Error(), path)
+		}
+		if !i.importAllowed(abs) {
+			return "", newInterpreterError("Permission denied: cannot import "+name, path)
+		}
+		return abs, nil
+	}
+	return "", newInterpreterError("Cannot find module '"+name+"'", path)
+}
+
+// importAllowed reports whether path may be loaded as a module: it must lie
+// below the directory of the main script, a package, the search path or a
+// read root once symlinks are resolved.
+func (i *Interpreter) importAllowed(path string) bool {
+	path, err := realPath(path)
+	if err != nil {
+		return false
+	}
+	roots := slices.Concat(i.searchPath, i.capabilities.ReadRoots)
+	for _, dir := range i.packages {
+		roots = append(roots, dir)
+	}
+	if len(i.importing) > 0 {
+		roots = append(roots, filepath.Dir(i.importing[0]))
+	} else {
+		roots = append(roots, i.scriptDir)
+	}
+	return slices.ContainsFunc(roots, func(root string) bool { return within(root, path) })
+}
+
+// loadModule executes the module at path in its own global environment the
+// first time it is imported and returns its exported names. Top level names
+// starting with an underscore are private.
+func (i *Interpreter) loadModule(path string, tok token.Token) (types.Module, error) {
+	if module, ok := i.modules[path]; ok {
+		return module, nil
+	}
+	if index := slices.Index(i.importing, path); index >= 0 {
+		chain := make([]string, 0, len(i.importing)-index+1)
+		for _, p := range append(i.importing[index:], path) {
+			chain = append(chain, i.displayPath(p))
+		}
+		return types.Module{}, newInterpreterError("Import cycle: "+strings.Join(chain, " -> "), tok)
+	}
+	statements, err := parseModule(path)
+	if err != nil {
+		return types.Module{}, newInterpreterError("Error in module "+i.displayPath(path)+": "+err.Error(), tok)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := i.pushFrame("<module "+name+">", path); err != nil {
+		return types.Module{}, err
+	}
+	defer i.popFrame()
+	previousEnv, previousDir := i.environment, i.scriptDir
+	i.environment = NewEnvironment()
+	i.defineStdlib()
+	i.environment.NewScope()
+	i.scriptDir = filepath.Dir(path)
+	i.importing = append(i.importing, path)
+	defer func() {
+		i.environment, i.scriptDir = previousEnv, previousDir
+		i.importing = i.importing[:len(i.importing)-1]
+	}()
+	i.pushScope("module " + name)
+	defer i.popScope("module " + name)
+
+	if i.coverage != nil {
+		i.coverage.Register(path, statements)
+	}
+	if err := i.executeStatements(statements); err != nil {
+		var ret returnSignal
+		if errors.As(err, &ret) {
+			err = newInterpreterError("Cannot return from top-level code", ret.keyword)
+		}
+		var runtimeErr InterpreterError
+		if errors.As(err, &runtimeErr) {
+			return types.Module{}, newInterpreterError("Error in module "+i.displayPath(path)+": "+err.Error(), tok)
+		}
+		return types.Module{}, err
+	}
+	module := types.Module{
+		Name:    name,
+		Members: map[string]types.ClavType{},
+	}
+	for name, value := range i.environment.env[1] {
+		if !strings.HasPrefix(name, "_") {
+			module.Members[name] = value
+		}
+	}
+	if i.modules == nil {
+		i.modules = map[string]types.Module{}
+	}
+	i.modules[path] = module
+	return module, nil
+}
+
+func parseModule(path string) ([]parser.Stmt, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := scanner.NewScanner(string(source))
+	tokens, errs := s.Scan()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	statements, errs := p.Parse()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	return statements, nil
+}
+
+// displayPath shortens path for error messages, relative to the main script.
+func (i *Interpreter) displayPath(path string) string {
+	if len(i.importing) == 0 {
+		return path
+	}
+	rel, err := filepath.Rel(filepath.Dir(i.importing[0]), path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
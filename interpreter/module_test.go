@@ -0,0 +1,100 @@
+package interpreter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+// writeFiles creates files below dir from a map of relative paths to
+// contents.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"lib/greet.clav": `print "loading greet";
+var _private = "hidden";
+var name = "greet";
+fun hello(who) { return "hello " + who; }
+`,
+		"lib/counter.clav": `var count = 0;
+fun next() { count = count + 1; return count; }
+`,
+	})
+	source := `
+import "lib/greet.clav" as g;
+import "lib/greet.clav";
+from "lib/greet.clav" import hello, name;
+print g.hello("ann");
+print greet.name;
+print hello(name);
+try { print g._private; } catch (e) { print e; }
+import "lib/counter.clav";
+from "lib/counter.clav" import next;
+counter.next();
+print next();
+var count = 10;
+print next();
+`
+	out, err := run(t, source, interpreter.WithScriptPath(filepath.Join(dir, "main.clav")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Modules run once however often they are imported, in an environment
+	// of their own.
+	want := "loading greet\nhello ann\ngreet\nhello greet\nUndefined property '_private' on <module greet>\n2\n3\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestImportSearchPath(t *testing.T) {
+	dir, lib := t.TempDir(), t.TempDir()
+	writeFiles(t, lib, map[string]string{"util.clav": `var where = "search path";`})
+	writeFiles(t, dir, map[string]string{"nested/util.clav": `import "../inner.clav"; var where = inner.where;`, "inner.clav": `var where = "relative";`})
+	source := `import "util.clav"; print util.where; import "nested/util.clav" as nested; print nested.where;`
+	out, err := run(t, source, interpreter.WithScriptPath(filepath.Join(dir, "main.clav")), interpreter.WithSearchPath(lib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "search path\nrelative\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.clav":   `import "b.clav";`,
+		"b.clav":   `import "a.clav";`,
+		"bad.clav": `var x = ;`,
+		"ok.clav":  `var x = 1;`,
+	})
+	for source, want := range map[string]string{
+		`import "a.clav";`:           "Import cycle: a.clav -> b.clav -> a.clav",
+		`import "missing.clav";`:     "Cannot find module 'missing.clav'",
+		`from "ok.clav" import y;`:   "Module 'ok' has no exported name 'y'",
+		`import "bad.clav";`:         "Error in module bad.clav",
+		`import "ok.clav"; print x;`: "Undefined variable 'x'",
+	} {
+		_, err := run(t, source, interpreter.WithScriptPath(filepath.Join(dir, "main.clav")))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", source, err, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// ExitError is returned when a script calls os.exit. It cannot be caught by
+// the script; hosts decide what exiting means, the CLI exits with Code.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("Script exited with status %d", e.Code)
+}
+
+// WithArgs sets the command line arguments visible to a script as os.args.
+func WithArgs(args []string) Option {
+	return func(i *Interpreter) {
+		i.args = args
+	}
+}
+
+func (i *Interpreter) osModule() types.Module {
+	return types.Module{Name: "os", Members: map[string]types.ClavType{
+		"args": stringList(i.args),
+		"env": native("os.env", 1, func(args []types.ClavType) (types.ClavType, error) {
+			if err := i.capabilities.require(CapEnv); err != nil {
+				return nil, err
+			}
+			name, err := stringArg("os.env", args, 0)
+			if err != nil {
+				return nil, err
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return types.String{Value: value}, nil
+			}
+			return types.Nil{}, nil
+		}),
+		"setenv": native("os.setenv", 2, func(args []types.ClavType) (types.ClavType, error) {
+			if err := i.capabilities.require(CapEnv); err != nil {
+				return nil, err
+			}
+			name, err := stringArg("os.setenv", args, 0)
+			if err != nil {
+				return nil, err
+			}
+			value, err := stringArg("os.setenv", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.Setenv(name, value); err != nil {
+				return nil, fmt.Errorf("os.setenv: %w", err)
+			}
+			return types.Nil{}, nil
+		}),
+		"cwd": native("os.cwd", 0, func([]types.ClavType) (types.ClavType, error) {
+			if err := i.capabilities.require(CapEnv); err != nil {
+				return nil, err
+			}
+			dir, err := os.Getwd()
+			if err != nil {
+				return nil, fmt.Errorf("os.cwd: %w", err)
+			}
+			return types.String{Value: dir}, nil
+		}),
+		"exit": native("os.exit", 1, func(args []types.ClavType) (types.ClavType, error) {
+			code, err := intArg("os.exit", args, 0)
+			if err != nil {
+				return nil, err
+			}
+			return nil, ExitError{Code: code}
+		}),
+	}}
+}
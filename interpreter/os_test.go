@@ -0,0 +1,64 @@
+package interpreter_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestOS(t *testing.T) {
+	t.Setenv("CLAV_OS_TEST", "from host")
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := `
+print os.args;
+print os.env("CLAV_OS_TEST");
+print os.env("CLAV_OS_TEST_UNSET");
+os.setenv("CLAV_OS_TEST", "from script");
+print os.env("CLAV_OS_TEST");
+print os.cwd();
+`
+	out, err := run(t, source,
+		interpreter.WithArgs([]string{"a", "b c"}),
+		interpreter.WithCapabilities(interpreter.Capabilities{Env: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[a, b c]\nfrom host\nnil\nfrom script\n" + cwd + "\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if got := os.Getenv("CLAV_OS_TEST"); got != "from script" {
+		t.Errorf("os.setenv left %q", got)
+	}
+}
+
+func TestOSEnvDenied(t *testing.T) {
+	for _, call := range []string{`os.env("HOME")`, `os.setenv("A", "b")`, `os.cwd()`} {
+		out, err := run(t, `try { `+call+`; } catch (e) { print e; }`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out, "Permission denied: 'env' capability is not granted") {
+			t.Errorf("%s: %q", call, out)
+		}
+	}
+}
+
+func TestExit(t *testing.T) {
+	out, err := run(t, `print "before"; try { os.exit(3); } catch (e) { print "caught"; } print "after";`)
+	var exit interpreter.ExitError
+	if !errors.As(err, &exit) || exit.Code != 3 {
+		t.Fatalf("got error %v, want an ExitError", err)
+	}
+	if out != "before\n" {
+		t.Errorf("the program kept running after exit: %q", out)
+	}
+	if _, err := run(t, `os.exit(1.5);`); err == nil || errors.As(err, &exit) {
+		t.Errorf("non-integer status: got error %v", err)
+	}
+}
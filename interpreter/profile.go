@@ -0,0 +1,20 @@
+package interpreter
+
+// Profiler is told about every statement the Interpreter runs, blocks
+// aside, with the line it is on, about every frame pushed and popped, and
+// about every value it allocates, as counted by Allocated. It can follow
+// the stack from these without calling Stack, whose cost grows with the
+// depth of the stack.
+type Profiler interface {
+	Statement(i *Interpreter, line int)
+	PushFrame(i *Interpreter, function, file string)
+	PopFrame(i *Interpreter)
+	Allocate(i *Interpreter, bytes int)
+}
+
+// WithProfiler installs p as the Interpreter's profiler.
+func WithProfiler(p Profiler) Option {
+	return func(i *Interpreter) {
+		i.profiler = p
+	}
+}
@@ -0,0 +1,96 @@
+package interpreter
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// WithSeed seeds the random module so that runs are reproducible. Without
+// it every Interpreter is seeded unpredictably.
+func WithSeed(seed uint64) Option {
+	return func(i *Interpreter) {
+		i.random = newRandom(seed)
+	}
+}
+
+func newRandom(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+func (i *Interpreter) randomModule() types.Module {
+	if i.random == nil {
+		i.random = newRandom(rand.Uint64())
+	}
+	return types.Module{Name: "random", Members: map[string]types.ClavType{
+		"float": native("random.float", 0, func([]types.ClavType) (types.ClavType, error) {
+			return types.Number{Value: i.random.Float64()}, nil
+		}),
+		"int":     native("random.int", 2, i.randomInt),
+		"choice":  native("random.choice", 1, i.randomChoice),
+		"shuffle": native("random.shuffle", 1, i.randomShuffle),
+		"sample":  native("random.sample", 2, i.randomSample),
+	}}
+}
+
+// randomInt returns an integer between its two arguments, inclusive.
+func (i *Interpreter) randomInt(args []types.ClavType) (types.ClavType, error) {
+	lo, err := intArg("random.int", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := intArg("random.int", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if lo > hi {
+		return nil, errors.New("random.int: lower bound is greater than upper bound")
+	}
+	// The span is computed unsigned so that bounds far apart cannot overflow.
+	offset := i.random.Uint64N(uint64(hi-lo) + 1)
+	return types.Number{Value: float64(lo + int(offset))}, nil
+}
+
+func (i *Interpreter) randomChoice(args []types.ClavType) (types.ClavType, error) {
+	list, err := listArg("random.choice", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Elements) == 0 {
+		return nil, errors.New("random.choice: list is empty")
+	}
+	return list.Elements[i.random.IntN(len(list.Elements))], nil
+}
+
+// randomShuffle shuffles a list in place.
+func (i *Interpreter) randomShuffle(args []types.ClavType) (types.ClavType, error) {
+	list, err := listArg("random.shuffle", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	i.random.Shuffle(len(list.Elements), func(a, b int) {
+		list.Elements[a], list.Elements[b] = list.Elements[b], list.Elements[a]
+	})
+	return types.Nil{}, nil
+}
+
+// randomSample returns a new list of count distinct elements of a list.
+func (i *Interpreter) randomSample(args []types.ClavType) (types.ClavType, error) {
+	list, err := listArg("random.sample", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := intArg("random.sample", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || count > len(list.Elements) {
+		return nil, errors.New("random.sample: sample size out of range")
+	}
+	sample := make([]types.ClavType, 0, count)
+	for _, j := range i.random.Perm(len(list.Elements))[:count] {
+		sample = append(sample, list.Elements[j])
+	}
+	return types.NewList(sample...), nil
+}
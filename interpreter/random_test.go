@@ -0,0 +1,37 @@
+package interpreter_test
+
+import (
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestRandomSeedIsReproducible(t *testing.T) {
+	source := `
+var l = [1, 2, 3, 4, 5, 6, 7, 8];
+random.shuffle(l);
+print l;
+print random.int(1, 1000);
+print random.float();
+print random.sample(l, 3);
+print random.choice(l);
+`
+	first, err := run(t, source, interpreter.WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := run(t, source, interpreter.WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("same seed produced different runs:\n%s\n%s", first, second)
+	}
+	other, err := run(t, source, interpreter.WithSeed(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == other {
+		t.Error("different seeds produced identical runs")
+	}
+}
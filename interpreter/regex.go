@@ -0,0 +1,191 @@
+package interpreter
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// Pattern is a compiled regular expression. Its methods are read as
+// properties, for example pattern.test("text").
+type Pattern struct {
+	types.Foreign
+	re          *regexp.Regexp
+	interpreter *Interpreter
+}
+
+func (i *Interpreter) regexModule() types.Module {
+	return types.Module{Name: "regex", Members: map[string]types.ClavType{
+		"compile": native("regex.compile", 1, i.regexCompile),
+		"escape": native("regex.escape", 1, func(args []types.ClavType) (types.ClavType, error) {
+			s, err := stringArg("regex.escape", args, 0)
+			if err != nil {
+				return nil, err
+			}
+			return types.String{Value: regexp.QuoteMeta(s)}, nil
+		}),
+	}}
+}
+
+func (i *Interpreter) regexCompile(args []types.ClavType) (types.ClavType, error) {
+	expr, err := stringArg("regex.compile", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.New("regex.compile: " + err.Error())
+	}
+	return &Pattern{re: re, interpreter: i}, nil
+}
+
+func (p *Pattern) String() string {
+	return "<regex " + p.re.String() + ">"
+}
+
+func (p *Pattern) Property(name string) (types.ClavType, bool) {
+	fn := "regex." + name
+	switch name {
+	case "source":
+		return types.String{Value: p.re.String()}, true
+	case "test":
+		return p.textMethod(fn, func(s string) types.ClavType {
+			return types.Boolean{Value: p.re.MatchString(s)}
+		}), true
+	case "find":
+		return p.textMethod(fn, func(s string) types.ClavType {
+			loc := p.re.FindStringIndex(s)
+			if loc == nil {
+				return types.Nil{}
+			}
+			return types.String{Value: s[loc[0]:loc[1]]}
+		}), true
+	case "findAll":
+		return native(fn, 1, p.findAll), true
+	case "split":
+		return p.textMethod(fn, func(s string) types.ClavType {
+			return stringList(p.re.Split(s, -1))
+		}), true
+	case "groups":
+		return p.textMethod(fn, p.groups), true
+	case "replace":
+		return native(fn, 2, p.replace), true
+	}
+	return nil, false
+}
+
+// textMethod builds a method taking a single String argument.
+func (p *Pattern) textMethod(fn string, method func(string) types.ClavType) types.NativeFunction {
+	return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+		s, err := stringArg(fn, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return method(s), nil
+	})
+}
+
+// groups returns the named capture groups of the first match as a map, or
+// nil when there is no match. Groups that did not participate are nil.
+func (p *Pattern) groups(s string) types.ClavType {
+	match := p.re.FindStringSubmatchIndex(s)
+	if match == nil {
+		return types.Nil{}
+	}
+	groups := types.NewMap()
+	for j, name := range p.re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if match[2*j] < 0 {
+			groups.Set(name, types.Nil{})
+		} else {
+			groups.Set(name, types.String{Value: s[match[2*j]:match[2*j+1]]})
+		}
+	}
+	return groups
+}
+
+// matches returns the indexes of every match in s and of its groups. They
+// are found in batches of growing size, reserving memory before each one,
+// so that a pattern matching everywhere cannot exhaust the host.
+func (p *Pattern) matches(s string) ([][]int, error) {
+	for n := 64; ; n *= 2 {
+		if err := p.interpreter.reserve(n * (p.re.NumSubexp() + 1) * valueOverhead); err != nil {
+			return nil, err
+		}
+		found := p.re.FindAllStringSubmatchIndex(s, n)
+		if len(found) < n {
+			return found, nil
+		}
+	}
+}
+
+func (p *Pattern) findAll(args []types.ClavType) (types.ClavType, error) {
+	s, err := stringArg("regex.findAll", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := p.matches(s)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]string, len(matches))
+	for j, match := range matches {
+		found[j] = s[match[0]:match[1]]
+	}
+	return stringList(found), nil
+}
+
+// replace substitutes every match. The replacement is either a String, which
+// may refer to groups as $1 or ${name}, or a function called with each
+// matched text that returns its replacement. The result is reserved as it
+// grows, one match at a time.
+func (p *Pattern) replace(args []types.ClavType) (types.ClavType, error) {
+	s, err := stringArg("regex.replace", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	var expand func(dst []byte, match []int) ([]byte, error)
+	switch replacement := args[1].(type) {
+	case types.String:
+		expand = func(dst []byte, match []int) ([]byte, error) {
+			return p.re.ExpandString(dst, replacement.Value, s, match), nil
+		}
+	case types.Callable:
+		if replacement.Arity() != 1 && replacement.Arity() != types.Variadic {
+			return nil, errors.New("regex.replace: callback must take 1 argument")
+		}
+		expand = func(dst []byte, match []int) ([]byte, error) {
+			value, err := replacement.Call([]types.ClavType{types.String{Value: s[match[0]:match[1]]}})
+			if err != nil {
+				return nil, err
+			}
+			text, ok := value.(types.String)
+			if !ok {
+				return nil, errors.New("regex.replace: callback returned " + typeName(value) + ", not a String")
+			}
+			return append(dst, text.Value...), nil
+		}
+	default:
+		return nil, argError("regex.replace", 1, "a String or function", args[1])
+	}
+	matches, err := p.matches(s)
+	if err != nil {
+		return nil, err
+	}
+	var result []byte
+	last := 0
+	for _, match := range matches {
+		result = append(result, s[last:match[0]]...)
+		if result, err = expand(result, match); err != nil {
+			return nil, err
+		}
+		last = match[1]
+		if err := p.interpreter.reserve(len(result) + len(s) - last); err != nil {
+			return nil, err
+		}
+	}
+	return types.String{Value: string(append(result, s[last:]...))}, nil
+}
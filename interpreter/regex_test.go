@@ -0,0 +1,75 @@
+package interpreter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestRegex(t *testing.T) {
+	source := `
+var re = regex.compile("(?P<key>[a-z]+)=(?P<val>[0-9]+)");
+print re.test("a=1");
+print re.find("x b=22");
+print re.findAll("b=22 c=3");
+print re.groups("b=22");
+print re.groups("none");
+print re.replace("b=22 c=3", "${val}:${key}");
+fun shout(match) { return match.upper(); }
+print re.replace("b=22 c=3", shout);
+print regex.compile(",\s*").split("a, b,c");
+print regex.compile("x*").replace("abc", "-");
+print regex.compile("x*").findAll("axxb");
+try { regex.compile("("); } catch (e) { print "caught"; }
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `true
+b=22
+[b=22, c=3]
+{key: b, val: 22}
+nil
+22:b 3:c
+B=22 C=3
+[a, b, c]
+-a-b-c-
+[, xx, ]
+caught
+`
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestRegexCallbackMustReturnString(t *testing.T) {
+	source := `
+fun count(match) { return 1; }
+regex.compile("a").replace("banana", count);
+`
+	if _, err := run(t, source); err == nil {
+		t.Error("expected an error for a non-string replacement")
+	}
+}
+
+// TestRegexMemoryLimit checks that results are reserved before they are
+// built, so a failing call never allocates past the limit.
+func TestRegexMemoryLimit(t *testing.T) {
+	limits := interpreter.WithLimits(interpreter.Limits{MaxMemory: 1 << 16})
+	for _, source := range []string{
+		`regex.compile("").findAll("a".repeat(10000));`,
+		`regex.compile("a").replace("a".repeat(1000), "x".repeat(100));`,
+		`fun widen(match) { return "x".repeat(100); }
+regex.compile("a").replace("a".repeat(1000), widen);`,
+	} {
+		i := interpreter.NewInterpreter(limits)
+		if err := i.Interpret(parse(t, source)); !errors.As(err, new(interpreter.MemoryLimitError)) {
+			t.Errorf("%s: got error %v, want a MemoryLimitError", source, err)
+		}
+		if i.Allocated() > 1<<16 {
+			t.Errorf("%s: %d bytes were allocated past the limit", source, i.Allocated())
+		}
+	}
+}
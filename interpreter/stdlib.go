@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// defineStdlib binds the native standard library modules as globals. The
+// modules are built once and shared by every clav module imported.
+func (i *Interpreter) defineStdlib() {
+	if i.stdlib == nil {
+		i.stdlib = []types.Module{
+			mathModule(),
+			i.fsModule(),
+			jsonModule(),
+			i.timeModule(),
+			i.randomModule(),
+			i.regexModule(),
+			i.osModule(),
+			i.execModule(),
+			assertModule(),
+		}
+	}
+	for _, module := range i.stdlib {
+		i.environment.Define(module.Name, module)
+	}
+}
+
+// Globals lists the names defined before any script code runs.
+func Globals() []string {
+	i := NewInterpreter()
+	names := make([]string, len(i.stdlib))
+	for j, module := range i.stdlib {
+		names[j] = module.Name
+	}
+	return names
+}
+
+func native(name string, arity int, fn func(args []types.ClavType) (types.ClavType, error)) types.NativeFunction {
+	return types.NativeFunction{Name: name, Params: arity, Fn: fn}
+}
+
+// typeName is the name of value's type as shown in error messages.
+func typeName(value types.ClavType) string {
+	if value == nil {
+		return "Nil"
+	}
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func argError(fn string, index int, want string, got types.ClavType) error {
+	return fmt.Errorf("%s: argument %d must be %s, got %s", fn, index+1, want, typeName(got))
+}
+
+func numberArg(fn string, args []types.ClavType, index int) (float64, error) {
+	if n, ok := args[index].(types.Number); ok {
+		return n.Value, nil
+	}
+	return 0, argError(fn, index, "a Number", args[index])
+}
+
+func stringArg(fn string, args []types.ClavType, index int) (string, error) {
+	if s, ok := args[index].(types.String); ok {
+		return s.Value, nil
+	}
+	return "", argError(fn, index, "a String", args[index])
+}
+
+// intArg returns an argument that must be a Number holding an integer.
+func intArg(fn string, args []types.ClavType, index int) (int, error) {
+	n, ok := args[index].(types.Number)
+	// The bounds also reject infinities and values int cannot represent.
+	if !ok || n.Value != math.Trunc(n.Value) || n.Value < math.MinInt || n.Value >= math.MaxInt {
+		return 0, argError(fn, index, "an integer", args[index])
+	}
+	return int(n.Value), nil
+}
+
+func listArg(fn string, args []types.ClavType, index int) (*types.List, error) {
+	if l, ok := args[index].(*types.List); ok {
+		return l, nil
+	}
+	return nil, argError(fn, index, "a List", args[index])
+}
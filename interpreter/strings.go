@@ -0,0 +1,165 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// stringMethod binds the method called name to the string s. Indexes are
+// counted in runes rather than bytes.
+func (i *Interpreter) stringMethod(s string, name string) (types.NativeFunction, bool) {
+	fn := "string." + name
+	switch name {
+	case "length":
+		return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+			return types.Number{Value: float64(utf8.RuneCountInString(s))}, nil
+		}), true
+	case "substring":
+		return native(fn, 2, func(args []types.ClavType) (types.ClavType, error) {
+			runes := []rune(s)
+			start, err := intArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			end, err := intArg(fn, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			if start < 0 || end > len(runes) || start > end {
+				return nil, errors.New(fn + ": range out of bounds")
+			}
+			return types.String{Value: string(runes[start:end])}, nil
+		}), true
+	case "indexOf":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			sub, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			index := strings.Index(s, sub)
+			if index >= 0 {
+				index = utf8.RuneCountInString(s[:index])
+			}
+			return types.Number{Value: float64(index)}, nil
+		}), true
+	case "contains":
+		return stringPredicate(fn, s, strings.Contains), true
+	case "startsWith":
+		return stringPredicate(fn, s, strings.HasPrefix), true
+	case "endsWith":
+		return stringPredicate(fn, s, strings.HasSuffix), true
+	case "split":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			sep, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			return stringList(strings.Split(s, sep)), nil
+		}), true
+	case "join":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			list, err := listArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			parts := make([]string, len(list.Elements))
+			size := 0
+			for j, e := range list.Elements {
+				part, ok := e.(types.String)
+				if !ok {
+					return nil, errors.New(fn + ": list element " + typeName(e) + " is not a String")
+				}
+				parts[j] = part.Value
+				size += len(part.Value)
+			}
+			if seps := len(parts) - 1; seps > 0 {
+				if len(s) > (math.MaxInt-size)/seps {
+					return nil, errors.New(fn + ": result too large")
+				}
+				size += len(s) * seps
+			}
+			if err := i.reserve(size); err != nil {
+				return nil, err
+			}
+			return types.String{Value: strings.Join(parts, s)}, nil
+		}), true
+	case "replace":
+		return native(fn, 2, func(args []types.ClavType) (types.ClavType, error) {
+			old, err := stringArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			replacement, err := stringArg(fn, args, 1)
+			if err != nil {
+				return nil, err
+			}
+			// An empty old matches before every rune and at the end, which
+			// strings.Count counts the same way.
+			count := strings.Count(s, old)
+			growth := len(replacement) - len(old)
+			if growth > 0 && count > (math.MaxInt-len(s))/growth {
+				return nil, errors.New(fn + ": result too large")
+			}
+			if err := i.reserve(len(s) + count*growth); err != nil {
+				return nil, err
+			}
+			return types.String{Value: strings.ReplaceAll(s, old, replacement)}, nil
+		}), true
+	case "repeat":
+		return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+			count, err := intArg(fn, args, 0)
+			if err != nil {
+				return nil, err
+			}
+			if count < 0 {
+				return nil, errors.New(fn + ": negative repeat count")
+			}
+			if len(s) > 0 && count > math.MaxInt/len(s) {
+				return nil, errors.New(fn + ": result too large")
+			}
+			if err := i.reserve(len(s) * count); err != nil {
+				return nil, err
+			}
+			return types.String{Value: strings.Repeat(s, count)}, nil
+		}), true
+	case "trim":
+		return stringTransform(fn, s, strings.TrimSpace), true
+	case "upper":
+		return stringTransform(fn, s, strings.ToUpper), true
+	case "lower":
+		return stringTransform(fn, s, strings.ToLower), true
+	case "runes":
+		return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+			return stringList(strings.Split(s, "")), nil
+		}), true
+	}
+	return types.NativeFunction{}, false
+}
+
+func stringTransform(fn string, s string, transform func(string) string) types.NativeFunction {
+	return native(fn, 0, func([]types.ClavType) (types.ClavType, error) {
+		return types.String{Value: transform(s)}, nil
+	})
+}
+
+func stringPredicate(fn string, s string, predicate func(string, string) bool) types.NativeFunction {
+	return native(fn, 1, func(args []types.ClavType) (types.ClavType, error) {
+		other, err := stringArg(fn, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return types.Boolean{Value: predicate(s, other)}, nil
+	})
+}
+
+func stringList(parts []string) *types.List {
+	elements := make([]types.ClavType, len(parts))
+	for j, part := range parts {
+		elements[j] = types.String{Value: part}
+	}
+	return types.NewList(elements...)
+}
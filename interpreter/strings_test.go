@@ -0,0 +1,48 @@
+package interpreter_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStringMethods(t *testing.T) {
+	source := `
+print "abc".upper();
+print "ABC".lower();
+print "  padded  ".trim() + "|";
+print "héllo wörld".length();
+print "héllo".substring(1, 3);
+print "héllo".indexOf("l");
+print "hello".indexOf("z");
+print "hello".contains("ell");
+print "hello".startsWith("he");
+print "hello".endsWith("lo");
+print "a,b,c".split(",");
+print "-".join(["a", "b"]);
+print "hello".replace("l", "L");
+print "ab".repeat(3);
+print "日本語".runes();
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ABC\nabc\npadded|\n11\nél\n2\n-1\ntrue\ntrue\ntrue\n[a, b, c]\na-b\nheLLo\nababab\n[日, 本, 語]\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	for source, want := range map[string]string{
+		`"abc".substring(2, 5);`: "string.substring: range out of bounds",
+		`",".join(["a", 1]);`:    "string.join: list element Number is not a String",
+		`"ab".repeat(-1);`:       "string.repeat",
+		`"abc".missing();`:       "Undefined property 'missing'",
+	} {
+		_, err := run(t, source)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", source, err, want)
+		}
+	}
+}
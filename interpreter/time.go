@@ -0,0 +1,111 @@
+package interpreter
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/it-a-me/clavlang/types"
+)
+
+// The time module represents instants as seconds since the Unix epoch and
+// durations as seconds, both as Numbers.
+func (i *Interpreter) timeModule() types.Module {
+	start := i.clock.Now()
+	return types.Module{Name: "time", Members: map[string]types.ClavType{
+		"clock": native("time.clock", 0, func([]types.ClavType) (types.ClavType, error) {
+			if err := i.capabilities.require(CapTime); err != nil {
+				return nil, err
+			}
+			return types.Number{Value: i.clock.Now().Sub(start).Seconds()}, nil
+		}),
+		"now": native("time.now", 0, func([]types.ClavType) (types.ClavType, error) {
+			if err := i.capabilities.require(CapTime); err != nil {
+				return nil, err
+			}
+			return types.Number{Value: unixSeconds(i.clock.Now())}, nil
+		}),
+		"sleep":          native("time.sleep", 1, i.timeSleep),
+		"format":         native("time.format", 1, timeFormat),
+		"parse":          native("time.parse", 1, timeParse),
+		"duration":       native("time.duration", 1, timeDuration),
+		"formatDuration": native("time.formatDuration", 1, timeFormatDuration),
+	}}
+}
+
+func (i *Interpreter) timeSleep(args []types.ClavType) (types.ClavType, error) {
+	if err := i.capabilities.require(CapTime); err != nil {
+		return nil, err
+	}
+	d, err := durationArg("time.sleep", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.clock.Sleep(i.ctx, d); err != nil {
+		return nil, CanceledError{Err: err}
+	}
+	return types.Nil{}, nil
+}
+
+// timeFormat formats an instant as an RFC 3339 timestamp in UTC.
+func timeFormat(args []types.ClavType) (types.ClavType, error) {
+	seconds, err := numberArg("time.format", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return nil, argError("time.format", 0, "a finite Number", args[0])
+	}
+	whole, frac := math.Modf(seconds)
+	t := time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC()
+	return types.String{Value: t.Format(time.RFC3339Nano)}, nil
+}
+
+func timeParse(args []types.ClavType) (types.ClavType, error) {
+	text, err := stringArg("time.parse", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return nil, errors.New("time.parse: " + err.Error())
+	}
+	return types.Number{Value: unixSeconds(t)}, nil
+}
+
+// timeDuration parses a Go style duration such as "1h30m" into seconds.
+func timeDuration(args []types.ClavType) (types.ClavType, error) {
+	text, err := stringArg("time.duration", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return nil, errors.New("time.duration: " + err.Error())
+	}
+	return types.Number{Value: d.Seconds()}, nil
+}
+
+func timeFormatDuration(args []types.ClavType) (types.ClavType, error) {
+	d, err := durationArg("time.formatDuration", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	return types.String{Value: d.String()}, nil
+}
+
+func durationArg(fn string, args []types.ClavType, index int) (time.Duration, error) {
+	seconds, err := numberArg(fn, args, index)
+	if err != nil {
+		return 0, err
+	}
+	d := seconds * float64(time.Second)
+	if math.IsNaN(d) || d < math.MinInt64 || d > math.MaxInt64 {
+		return 0, argError(fn, index, "a duration in seconds", args[index])
+	}
+	return time.Duration(d), nil
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
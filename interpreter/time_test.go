@@ -0,0 +1,35 @@
+package interpreter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestFakeClock(t *testing.T) {
+	clock := interpreter.NewFakeClock(time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC))
+	source := `
+var start = time.clock();
+print time.format(time.now());
+time.sleep(90);
+print time.clock() - start;
+print time.format(time.now());
+`
+	out, err := run(t, source,
+		interpreter.WithClock(clock),
+		interpreter.WithCapabilities(interpreter.Capabilities{Time: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-02-03T04:05:06Z\n90\n2024-02-03T04:06:36Z\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestTimeRequiresCapability(t *testing.T) {
+	if _, err := run(t, `time.now();`); err == nil {
+		t.Error("expected permission error")
+	}
+}
@@ -0,0 +1,39 @@
+package interpreter
+
+import (
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// Tracer follows the Interpreter step by step. Statement is called before
+// each statement runs, blocks aside, and Expression once an expression has
+// been evaluated, so the parts of an expression are reported before the
+// whole. PushScope and PopScope bracket every scope the Interpreter enters,
+// named by what opened it: "block", "catch e", "call f" or "module m".
+type Tracer interface {
+	Statement(i *Interpreter, stmt parser.Stmt)
+	Expression(i *Interpreter, expr parser.Expr, value types.ClavType)
+	PushScope(i *Interpreter, scope string)
+	PopScope(i *Interpreter, scope string)
+}
+
+// WithTracer installs t as the Interpreter's tracer.
+func WithTracer(t Tracer) Option {
+	return func(i *Interpreter) {
+		i.tracer = t
+	}
+}
+
+// pushScope tells the tracer that scope was entered. It must be paired
+// with popScope.
+func (i *Interpreter) pushScope(scope string) {
+	if i.tracer != nil {
+		i.tracer.PushScope(i, scope)
+	}
+}
+
+func (i *Interpreter) popScope(scope string) {
+	if i.tracer != nil {
+		i.tracer.PopScope(i, scope)
+	}
+}
@@ -0,0 +1,48 @@
+package interpreter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func TestTry(t *testing.T) {
+	source := `
+try {
+  print "in try";
+  var x = 1 + "a";
+  print "skipped";
+} catch (e) {
+  print "caught: " + e;
+}
+var e = "outer";
+try {
+  try { math.floor("x"); } catch (inner) { math.sqrt(inner); }
+} catch (e) {
+  print e;
+}
+print e;
+try { print "fine"; } catch (e) { print "not run"; }
+`
+	out, err := run(t, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "in try\ncaught: Cannot add values of different types\n" +
+		"math.sqrt: argument 1 must be a Number, got String\nouter\nfine\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestTryDoesNotCatchLimits(t *testing.T) {
+	source := `try { var a = 1; var b = 2; var c = 3; } catch (e) { print "caught"; }`
+	out, err := run(t, source, interpreter.WithLimits(interpreter.Limits{MaxSteps: 4}))
+	if !errors.As(err, new(interpreter.StepLimitError)) {
+		t.Errorf("got error %v, want a StepLimitError", err)
+	}
+	if out != "" {
+		t.Errorf("the step limit was caught: %q", out)
+	}
+}
@@ -0,0 +1,190 @@
+// Package lint finds likely mistakes in clav programs by walking their
+// syntax tree.
+//
+// Every rule runs by default. A Config narrows the set and single findings
+// are silenced with a comment naming the rules to ignore, either at the end
+// of the offending line or on the line above it:
+//
+//	var unused = 1; // clav:ignore unused-variable
+//
+//	// clav:ignore shadow, unused-variable
+//	var name = "inner";
+//
+// A bare `// clav:ignore` silences every rule.
+package lint
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+)
+
+const (
+	UnusedVariable       = "unused-variable"
+	Shadow               = "shadow"
+	Unreachable          = "unreachable"
+	MixedComparison      = "mixed-comparison"
+	UndeclaredAssignment = "undeclared-assignment"
+)
+
+// ignoreDirective starts a suppression comment.
+const ignoreDirective = "clav:ignore"
+
+type Rule struct {
+	Name string
+	Doc  string
+}
+
+// Rules describes every rule in the order they are documented.
+func Rules() []Rule {
+	return []Rule{
+		{UnusedVariable, "a variable or function declared in a block or function is never read"},
+		{Shadow, "a declaration hides a variable of an enclosing scope"},
+		{Unreachable, "a statement follows a return in the same block"},
+		{MixedComparison, "== or != compares literals of different types, which always fails"},
+		{UndeclaredAssignment, "a value is assigned to a name that was never declared"},
+	}
+}
+
+// Diagnostic is a single finding. File is left to the caller to fill in.
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", d.File, d.Line, d.Message, d.Rule)
+}
+
+// Config selects the rules to run. When Enable is empty every rule is
+// enabled; the rules in Disable are then turned off.
+type Config struct {
+	Enable  []string
+	Disable []string
+}
+
+// Validate reports rule names that do not exist.
+func (c Config) Validate() error {
+	var errs []error
+	for _, name := range slices.Concat(c.Enable, c.Disable) {
+		if !slices.ContainsFunc(Rules(), func(r Rule) bool { return r.Name == name }) {
+			errs = append(errs, fmt.Errorf("unknown lint rule %q", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c Config) enabled(rule string) bool {
+	if len(c.Enable) > 0 && !slices.Contains(c.Enable, rule) {
+		return false
+	}
+	return !slices.Contains(c.Disable, rule)
+}
+
+// Source lints a complete program. Source that does not scan or parse is
+// returned as an error.
+func Source(src []byte, cfg Config) ([]Diagnostic, error) {
+	s := scanner.NewScanner(string(src))
+	tokens, errs := s.Scan()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	return Check(tokens, stmts, cfg), nil
+}
+
+// Check lints the statements parsed from tokens. Diagnostics are sorted by
+// line.
+func Check(tokens []token.Token, stmts []parser.Stmt, cfg Config) []Diagnostic {
+	a := Analyze(tokens, stmts, -1)
+	ignored := suppressions(tokens)
+	var diags []Diagnostic
+	for _, d := range a.diags {
+		if cfg.enabled(d.Rule) && !ignored.covers(d) {
+			diags = append(diags, d)
+		}
+	}
+	slices.SortFunc(diags, func(a, b Diagnostic) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Message, b.Message))
+	})
+	return diags
+}
+
+// Analysis resolves the names of a program, as editors need to.
+type Analysis struct {
+	// Symbols holds every declaration, starting with the builtins.
+	Symbols []*Symbol
+	// Visible lists the names in scope at the cursor passed to Analyze.
+	Visible []string
+
+	diags []Diagnostic
+}
+
+// Analyze resolves every name in the statements parsed from tokens and,
+// unless cursor is negative, which names are in scope at that offset.
+func Analyze(tokens []token.Token, stmts []parser.Stmt, cursor int) *Analysis {
+	l := newLinter(tokens, cursor)
+	l.file(stmts)
+	return &Analysis{Symbols: l.symbols, Visible: l.visible, diags: l.diags}
+}
+
+// SymbolAt returns the symbol declared or used by the identifier covering
+// offset.
+func (a *Analysis) SymbolAt(offset int) (*Symbol, bool) {
+	covers := func(tok token.Token) bool {
+		return tok.Line > 0 && tok.Offset <= offset && offset <= tok.Offset+len(tok.Lexeme)
+	}
+	for _, symbol := range a.Symbols {
+		if covers(symbol.Name) || slices.ContainsFunc(symbol.Uses, covers) {
+			return symbol, true
+		}
+	}
+	return nil, false
+}
+
+// ignores maps lines to the rules ignored on them. An empty list ignores
+// every rule.
+type ignores map[int][]string
+
+func (ig ignores) covers(d Diagnostic) bool {
+	rules, ok := ig[d.Line]
+	return ok && (len(rules) == 0 || slices.Contains(rules, d.Rule))
+}
+
+// suppressions collects ignore comments. A trailing comment applies to the
+// line it is on and one on a line of its own to the next line of code.
+func suppressions(tokens []token.Token) ignores {
+	ig := ignores{}
+	add := func(line int, trivia []token.Trivia) {
+		for _, t := range trivia {
+			text := strings.TrimSpace(strings.TrimPrefix(t.Text, "//"))
+			rest, ok := strings.CutPrefix(text, ignoreDirective)
+			if t.Kind != token.Comment || !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+				continue
+			}
+			rules := strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+			if len(rules) == 0 {
+				ig[line] = []string{}
+			} else if existing, ok := ig[line]; !ok || len(existing) > 0 {
+				ig[line] = append(existing, rules...)
+			}
+		}
+	}
+	for _, tok := range tokens {
+		add(tok.Line, tok.Leading)
+		add(tok.Line, tok.Trailing)
+	}
+	return ig
+}
@@ -0,0 +1,140 @@
+package lint_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/lint"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+func check(t *testing.T, source string, cfg lint.Config) []string {
+	t.Helper()
+	diags, err := lint.Source([]byte(source), cfg)
+	if err != nil {
+		t.Fatalf("lint %q: %v", source, err)
+	}
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = strings.TrimPrefix(d.String(), ":")
+	}
+	return got
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"clean", `var a = 1; { var b = a; print b; } fun f(x) { return x; } print f(a);`, nil},
+		{"unused local", "{\n  var unused = 1;\n}", []string{"2: variable 'unused' is declared but never used (unused-variable)"}},
+		{"unused local function", "fun outer() {\n  fun inner() {}\n}", []string{"2: function 'inner' is declared but never used (unused-variable)"}},
+		{"unused global", `var global = 1;`, nil},
+		{"unused underscore", `{ var _ignored = 1; }`, nil},
+		{"unused parameter", `fun f(x) {} f(1);`, nil},
+		{"assignment is not use", "{\n  var a;\n  a = 1;\n}", []string{"2: variable 'a' is declared but never used (unused-variable)"}},
+		{"shadow block", "var name = 1;\n{\n  var name = 2;\n  print name;\n}", []string{"3: declaration of 'name' shadows the one on line 1 (shadow)"}},
+		{"shadow parameter", "var x = 1;\nfun f(x) { return x; }\nprint f(x);", []string{"2: declaration of 'x' shadows the one on line 1 (shadow)"}},
+		{"shadow stdlib", "fun f(math) { return math; }\nprint f;", []string{"1: declaration of 'math' shadows the standard library module (shadow)"}},
+		{"redeclare same scope", "var a = 1;\nvar a = 2;", nil},
+		{"unreachable", "fun f() {\n  return 1;\n  print 2;\n  print 3;\n}\nf();", []string{"3: unreachable code after return (unreachable)"}},
+		{"mixed comparison", `print 1 == "1"; print (nil) != false; print 1 == 2;`, []string{
+			"1: comparison of Nil with Boolean always fails (mixed-comparison)",
+			"1: comparison of Number with String always fails (mixed-comparison)",
+		}},
+		{"undeclared assignment", "missing = 1;\nvar later = 1;\nlater = 2;", []string{"1: assignment to undeclared variable 'missing' (undeclared-assignment)"}},
+		{"global assigned from function", "fun set() { counter = 1; }\nvar counter = 0;\nset();", nil},
+		{"imports declare names", `import "lib/util.clav"; import "x.clav" as y; from "z.clav" import a; util = 1; y = 2; a = 3;`, nil},
+		{"catch variable", `try { print 1; } catch (e) { print 2; }`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.source, lint.Config{}); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	source := `var a = 1;
+{
+  var a = 2; // clav:ignore shadow
+  // clav:ignore
+  var b = 3;
+  var c = 4; // clav:ignore unreachable
+  var d = 5; // clav:ignorestuff
+}`
+	want := []string{
+		"3: variable 'a' is declared but never used (unused-variable)",
+		"6: variable 'c' is declared but never used (unused-variable)",
+		"7: variable 'd' is declared but never used (unused-variable)",
+	}
+	if got := check(t, source, lint.Config{}); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	source := "var a = 1;\n{\n  var a = 2;\n}\nmissing = 1;"
+	tests := []struct {
+		cfg  lint.Config
+		want []string
+	}{
+		{lint.Config{Enable: []string{lint.Shadow}}, []string{"3: declaration of 'a' shadows the one on line 1 (shadow)"}},
+		{lint.Config{Disable: []string{lint.Shadow, lint.UnusedVariable}}, []string{"5: assignment to undeclared variable 'missing' (undeclared-assignment)"}},
+	}
+	for _, tt := range tests {
+		if got := check(t, source, tt.cfg); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+	if err := (lint.Config{Disable: []string{"nope"}}).Validate(); err == nil {
+		t.Error("Validate accepted an unknown rule")
+	}
+}
+
+func analyze(t *testing.T, source string, cursor int) *lint.Analysis {
+	t.Helper()
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	return lint.Analyze(tokens, stmts, cursor)
+}
+
+func TestAnalyze(t *testing.T) {
+	source := "var total = 1;\nfun add(x) {\n  return x + total;\n}\nprint add(total);"
+	inside := strings.Index(source, "return")
+	visible := analyze(t, source, inside).Visible
+	for _, name := range []string{"x", "add", "total", "math"} {
+		if !slices.Contains(visible, name) {
+			t.Errorf("%q is not visible inside add: %q", name, visible)
+		}
+	}
+	if visible := analyze(t, source, len(source)).Visible; slices.Contains(visible, "x") {
+		t.Errorf("parameter x is visible after add: %q", visible)
+	}
+
+	a := analyze(t, source, -1)
+	symbol, ok := a.SymbolAt(strings.LastIndex(source, "total"))
+	if !ok || symbol.Kind != lint.Variable || symbol.Name.Line != 1 || len(symbol.Uses) != 2 {
+		t.Errorf("SymbolAt(total) = %+v, %v", symbol, ok)
+	}
+	symbol, ok = a.SymbolAt(strings.Index(source, "x +"))
+	if !ok || symbol.Kind != lint.Parameter || symbol.Detail != "parameter x of fun add(x)" {
+		t.Errorf("SymbolAt(x) = %+v, %v", symbol, ok)
+	}
+	if _, ok := a.SymbolAt(strings.Index(source, "print")); ok {
+		t.Error("SymbolAt found a symbol on a keyword")
+	}
+}
@@ -0,0 +1,413 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/types"
+)
+
+type SymbolKind int
+
+const (
+	Builtin SymbolKind = iota
+	Variable
+	Function
+	Parameter
+	CatchVariable
+	Import
+)
+
+// Symbol is a declared name and every place it is referred to. Builtins
+// have no declaration in the source and a zero Name.Line.
+type Symbol struct {
+	Name token.Token
+	Kind SymbolKind
+	// Detail shows the declaration, such as "fun add(a, b)".
+	Detail string
+	// Uses holds the tokens reading or assigning the symbol.
+	Uses []token.Token
+
+	read bool
+}
+
+type scope map[string]*Symbol
+
+// linter resolves names the way the interpreter's environments do and
+// records every finding, leaving rule selection to Check.
+type linter struct {
+	scopes []scope
+	// globals holds every top-level name. Function bodies run after the
+	// script declared them, so they may use globals declared further down;
+	// such uses wait in hoisted until the whole file was walked.
+	globals    map[string]bool
+	hoisted    []token.Token
+	inFunction int
+	symbols    []*Symbol
+	diags      []Diagnostic
+
+	// cursor is a source offset, or -1, at which visible captures the
+	// names in scope. Scopes are located with the braces in the token
+	// stream: opens holds the offset of every '{' in order and closes maps
+	// it to the offset of the matching '}'.
+	cursor  int
+	visible []string
+	opens   []int
+	closes  map[int]int
+}
+
+func newLinter(tokens []token.Token, cursor int) *linter {
+	l := &linter{globals: map[string]bool{}, cursor: cursor, closes: map[int]int{}}
+	var stack []int
+	for _, tok := range tokens {
+		switch tok.Type {
+		case token.LeftBrace:
+			l.opens = append(l.opens, tok.Offset)
+			stack = append(stack, tok.Offset)
+		case token.RightBrace:
+			if len(stack) > 0 {
+				l.closes[stack[len(stack)-1]] = tok.Offset
+				stack = stack[:len(stack)-1]
+			}
+		case token.EOF:
+			// The parser lets the last blocks of a file go unclosed.
+			for _, open := range stack {
+				l.closes[open] = tok.Offset
+			}
+		}
+	}
+	return l
+}
+
+// braceAfter returns the offset of the first '{' after offset and of the
+// '}' closing it.
+func (l *linter) braceAfter(offset int) (int, int) {
+	i, _ := slices.BinarySearch(l.opens, offset+1)
+	if i == len(l.opens) {
+		return -1, -1
+	}
+	return l.opens[i], l.closes[l.opens[i]]
+}
+
+func (l *linter) report(rule string, line int, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) file(stmts []parser.Stmt) {
+	global := scope{}
+	for _, name := range interpreter.Globals() {
+		symbol := &Symbol{Name: token.Token{Type: token.Identifier, Lexeme: name}, Kind: Builtin, Detail: "standard library module " + name}
+		global[name] = symbol
+		l.symbols = append(l.symbols, symbol)
+		l.globals[name] = true
+	}
+	for _, stmt := range stmts {
+		for _, name := range declaredNames(stmt) {
+			l.globals[name.Lexeme] = true
+		}
+	}
+	l.scopes = []scope{global}
+	l.capture()
+	// Top-level names may be used by modules importing this one, so unused
+	// globals are not reported.
+	l.statements(stmts)
+	for _, use := range l.hoisted {
+		if symbol, ok := global[use.Lexeme]; ok {
+			symbol.Uses = append(symbol.Uses, use)
+			symbol.read = true
+		}
+	}
+}
+
+func declaredNames(stmt parser.Stmt) []token.Token {
+	switch s := stmt.(type) {
+	case parser.Var:
+		return []token.Token{s.Name}
+	case parser.Function:
+		return []token.Token{s.Name}
+	case parser.Import:
+		return importedNames(s)
+	}
+	return nil
+}
+
+// importedNames are the names an import binds, which for a plain import is
+// the module name taken from its file name.
+func importedNames(s parser.Import) []token.Token {
+	if len(s.Names) > 0 {
+		return s.Names
+	}
+	if s.Alias.Lexeme != "" {
+		return []token.Token{s.Alias}
+	}
+	path := s.Path.Literal.String()
+	path = path[strings.LastIndex(path, "/")+1:]
+	if dot := strings.LastIndex(path, "."); dot > 0 {
+		path = path[:dot]
+	}
+	name := s.Path
+	name.Lexeme = path
+	return []token.Token{name}
+}
+
+func (l *linter) statements(stmts []parser.Stmt) {
+	returned := false
+	for _, stmt := range stmts {
+		if returned {
+			l.report(Unreachable, parser.StmtToken(stmt).Line, "unreachable code after return")
+			returned = false
+		}
+		l.statement(stmt)
+		if _, ok := stmt.(parser.Return); ok {
+			returned = true
+		}
+		if l.cursor >= l.stmtEnd(stmt) {
+			l.capture()
+		}
+	}
+}
+
+// stmtEnd approximates where a statement ends: just after the closing
+// brace of the block it ends with or else where it starts.
+func (l *linter) stmtEnd(stmt parser.Stmt) int {
+	var end int
+	switch s := stmt.(type) {
+	case parser.Block:
+		end = l.closes[s.Brace.Offset]
+	case parser.Function:
+		_, end = l.braceAfter(s.Name.Offset)
+	case parser.Try:
+		_, end = l.braceAfter(s.Name.Offset)
+	default:
+		return parser.StmtToken(stmt).Offset
+	}
+	return end + 1
+}
+
+// block walks stmts in a new scope opened by the brace after offset.
+func (l *linter) block(offset int, stmts []parser.Stmt) {
+	l.beginScope()
+	l.enter(offset)
+	l.statements(stmts)
+	l.endScope()
+}
+
+// enter captures the names in scope when the cursor is inside the braces
+// after offset, before any statement there was walked.
+func (l *linter) enter(offset int) {
+	if open, end := l.braceAfter(offset); open >= 0 && open < l.cursor && l.cursor <= end {
+		l.capture()
+	}
+}
+
+func (l *linter) statement(stmt parser.Stmt) {
+	switch s := stmt.(type) {
+	case parser.Print:
+		l.expr(s.Inner)
+	case parser.Expression:
+		l.expr(s.Inner)
+	case parser.Var:
+		if s.Initializer != nil {
+			l.expr(s.Initializer)
+		}
+		l.declare(s.Name, Variable, "var "+s.Name.Lexeme)
+	case parser.Block:
+		l.block(s.Brace.Offset-1, s.Statements)
+	case parser.Function:
+		l.function(s)
+	case parser.Try:
+		l.block(s.Keyword.Offset, s.Body)
+		l.beginScope()
+		l.declare(s.Name, CatchVariable, "catch ("+s.Name.Lexeme+")")
+		l.enter(s.Name.Offset)
+		l.statements(s.Handler)
+		l.endScope()
+	case parser.Return:
+		if s.Value != nil {
+			l.expr(s.Value)
+		}
+	case parser.Import:
+		for _, name := range importedNames(s) {
+			detail := "import " + s.Path.Lexeme
+			if len(s.Names) > 0 {
+				detail = "from " + s.Path.Lexeme + " import " + name.Lexeme
+			}
+			l.declare(name, Import, detail)
+		}
+	}
+}
+
+func (l *linter) function(s parser.Function) {
+	params := make([]string, len(s.Params))
+	for i, param := range s.Params {
+		params[i] = param.Lexeme
+	}
+	signature := "fun " + s.Name.Lexeme + "(" + strings.Join(params, ", ") + ")"
+	// Declared first so the function can call itself.
+	l.declare(s.Name, Function, signature)
+	l.beginScope()
+	for _, param := range s.Params {
+		l.declare(param, Parameter, "parameter "+param.Lexeme+" of "+signature)
+	}
+	l.enter(s.Name.Offset)
+	l.inFunction++
+	l.statements(s.Body)
+	l.inFunction--
+	l.endScope()
+}
+
+func (l *linter) expr(expr parser.Expr) {
+	switch e := expr.(type) {
+	case parser.Binary:
+		l.comparison(e)
+		l.expr(e.Left)
+		l.expr(e.Right)
+	case parser.Grouping:
+		l.expr(e.Expression)
+	case parser.Unary:
+		l.expr(e.Right)
+	case parser.Variable:
+		l.use(e.Name, true)
+	case parser.Assign:
+		l.expr(e.Value)
+		if !l.use(e.Name, false) {
+			l.report(UndeclaredAssignment, e.Name.Line, "assignment to undeclared variable '%s'", e.Name.Lexeme)
+		}
+	case parser.Call:
+		l.expr(e.Callee)
+		for _, arg := range e.Arguments {
+			l.expr(arg)
+		}
+	case parser.Get:
+		l.expr(e.Object)
+	case parser.ListLiteral:
+		for _, element := range e.Elements {
+			l.expr(element)
+		}
+	}
+}
+
+// use resolves a reference to name and reports whether it is declared.
+func (l *linter) use(name token.Token, read bool) bool {
+	if symbol := l.lookup(name.Lexeme); symbol != nil {
+		symbol.Uses = append(symbol.Uses, name)
+		symbol.read = symbol.read || read
+		return true
+	}
+	if l.inFunction > 0 && l.globals[name.Lexeme] {
+		l.hoisted = append(l.hoisted, name)
+		return true
+	}
+	return false
+}
+
+// comparison reports equality checks between literals of different types,
+// which the interpreter rejects at runtime.
+func (l *linter) comparison(e parser.Binary) {
+	if e.Operator.Type != token.EqualEqual && e.Operator.Type != token.BangEqual {
+		return
+	}
+	left, ok := literal(e.Left)
+	if !ok {
+		return
+	}
+	right, ok := literal(e.Right)
+	if !ok {
+		return
+	}
+	if left != right {
+		l.report(MixedComparison, e.Operator.Line, "comparison of %s with %s always fails", left, right)
+	}
+}
+
+// literal returns the type of a possibly parenthesized literal.
+func literal(expr parser.Expr) (string, bool) {
+	for {
+		group, ok := expr.(parser.Grouping)
+		if !ok {
+			break
+		}
+		expr = group.Expression
+	}
+	lit, ok := expr.(parser.Literal)
+	if !ok {
+		return "", false
+	}
+	switch lit.Value.(type) {
+	case types.Number:
+		return "Number", true
+	case types.String:
+		return "String", true
+	case types.Boolean:
+		return "Boolean", true
+	case types.Nil:
+		return "Nil", true
+	}
+	return "", false
+}
+
+func (l *linter) beginScope() {
+	l.scopes = append(l.scopes, scope{})
+}
+
+func (l *linter) endScope() {
+	current := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+	for name, symbol := range current {
+		if symbol.read || strings.HasPrefix(name, "_") {
+			continue
+		}
+		switch symbol.Kind {
+		case Variable:
+			l.report(UnusedVariable, symbol.Name.Line, "variable '%s' is declared but never used", name)
+		case Function:
+			l.report(UnusedVariable, symbol.Name.Line, "function '%s' is declared but never used", name)
+		case Builtin, Parameter, CatchVariable, Import:
+		}
+	}
+}
+
+func (l *linter) declare(name token.Token, kind SymbolKind, detail string) {
+	current := l.scopes[len(l.scopes)-1]
+	if _, redeclared := current[name.Lexeme]; !redeclared {
+		if outer := l.lookup(name.Lexeme); outer != nil {
+			if outer.Kind == Builtin {
+				l.report(Shadow, name.Line, "declaration of '%s' shadows the standard library module", name.Lexeme)
+			} else {
+				l.report(Shadow, name.Line, "declaration of '%s' shadows the one on line %d", name.Lexeme, outer.Name.Line)
+			}
+		}
+	}
+	symbol := &Symbol{Name: name, Kind: kind, Detail: detail}
+	current[name.Lexeme] = symbol
+	l.symbols = append(l.symbols, symbol)
+}
+
+func (l *linter) lookup(name string) *Symbol {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		if symbol, ok := l.scopes[i][name]; ok {
+			return symbol
+		}
+	}
+	return nil
+}
+
+// capture records the names currently in scope. Captures happen in
+// source order for positions up to the cursor, so the last one wins.
+func (l *linter) capture() {
+	if l.cursor < 0 {
+		return
+	}
+	names := maps.Clone(l.globals)
+	for _, s := range l.scopes {
+		for name := range s {
+			names[name] = true
+		}
+	}
+	l.visible = slices.Sorted(maps.Keys(names))
+}
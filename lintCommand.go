@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/it-a-me/clavlang/lint"
+)
+
+const lintUsage = `usage: clav lint [flags] [paths...]
+
+Reports likely mistakes in .clav files, searching directories recursively.
+The exit status is 1 when anything was reported.
+`
+
+func lintCommand(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), lintUsage)
+		flags.PrintDefaults()
+	}
+	var cfg lint.Config
+	flags.Var((*listFlag)(&cfg.Enable), "enable", "run only the comma separated `rules`")
+	flags.Var((*listFlag)(&cfg.Disable), "disable", "skip the comma separated `rules`")
+	asJSON := flags.Bool("json", false, "print diagnostics as a JSON array")
+	listRules := flags.Bool("rules", false, "list the available rules and exit")
+	_ = flags.Parse(args)
+
+	if *listRules {
+		for _, rule := range lint.Rules() {
+			fmt.Printf("%-22s %s\n", rule.Name, rule.Doc)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	diags := []lint.Diagnostic{}
+	failed := false
+	for _, root := range paths {
+		err := walkSources(root, func(path string) error {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			found, err := lint.Source(src, cfg)
+			if err != nil {
+				log.Printf("%s: %s", path, strings.ReplaceAll(err.Error(), "\n", "; "))
+				failed = true
+				return nil
+			}
+			for _, d := range found {
+				d.File = path
+				diags = append(diags, d)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+	}
+	if failed || len(diags) > 0 {
+		os.Exit(1)
+	}
+}
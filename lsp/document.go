@@ -0,0 +1,145 @@
+package lsp
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/it-a-me/clavlang/lint"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+)
+
+// document is an open file, analysed every time its text changes.
+type document struct {
+	uri      string
+	text     string
+	tokens   []token.Token
+	stmts    []parser.Stmt
+	errs     []error
+	analysis *lint.Analysis
+}
+
+// lineError is implemented by scanner errors.
+type lineError interface {
+	Line() int
+	Message() string
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text}
+	s := scanner.NewScanner(text)
+	// The scanner skips what it cannot read, so the remaining tokens still
+	// parse into something worth resolving.
+	d.tokens, d.errs = s.Scan()
+	p := parser.NewParser(d.tokens)
+	stmts, errs := p.Parse()
+	d.stmts = stmts
+	d.errs = append(d.errs, errs...)
+	d.analysis = lint.Analyze(d.tokens, d.stmts, -1)
+	return d
+}
+
+// diagnostics reports scan and parse errors or, for documents without any,
+// what the linter finds.
+func (d *document) diagnostics() []Diagnostic {
+	diags := []Diagnostic{}
+	for _, err := range d.errs {
+		diag := Diagnostic{Severity: SeverityError, Source: "clav", Message: err.Error()}
+		var lineErr lineError
+		var parseErr parser.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			diag.Range = d.tokenRange(parseErr.Token)
+			diag.Message = parseErr.Context
+		case errors.As(err, &lineErr):
+			diag.Range = d.lineRange(lineErr.Line())
+			diag.Message = lineErr.Message()
+		}
+		diags = append(diags, diag)
+	}
+	if len(d.errs) > 0 {
+		return diags
+	}
+	for _, found := range lint.Check(d.tokens, d.stmts, lint.Config{}) {
+		diags = append(diags, Diagnostic{
+			Range:    d.lineRange(found.Line),
+			Severity: SeverityWarning,
+			Code:     found.Rule,
+			Source:   "clav lint",
+			Message:  found.Message,
+		})
+	}
+	return diags
+}
+
+func (d *document) symbolAt(pos Position) (*lint.Symbol, bool) {
+	return d.analysis.SymbolAt(d.offset(pos))
+}
+
+func (d *document) location(tok token.Token) Location {
+	return Location{URI: d.uri, Range: d.tokenRange(tok)}
+}
+
+func (d *document) tokenRange(tok token.Token) Range {
+	return Range{Start: d.position(tok.Offset), End: d.position(tok.Offset + len(tok.Lexeme))}
+}
+
+// lineRange covers the text of a 1 based line without its indentation.
+func (d *document) lineRange(line int) Range {
+	start := 0
+	for range line - 1 {
+		next := strings.IndexByte(d.text[start:], '\n')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	end := strings.IndexByte(d.text[start:], '\n')
+	if end < 0 {
+		end = len(d.text)
+	} else {
+		end += start
+	}
+	indent := len(d.text[start:end]) - len(strings.TrimLeft(d.text[start:end], " \t"))
+	return Range{Start: d.position(start + indent), End: d.position(end)}
+}
+
+// position converts a byte offset to a position, whose character counts
+// UTF-16 code units as the protocol requires.
+func (d *document) position(offset int) Position {
+	offset = min(offset, len(d.text))
+	line := strings.Count(d.text[:offset], "\n")
+	lineStart := strings.LastIndexByte(d.text[:offset], '\n') + 1
+	return Position{Line: line, Character: utf16Len(d.text[lineStart:offset])}
+}
+
+func (d *document) offset(pos Position) int {
+	offset := 0
+	for range pos.Line {
+		next := strings.IndexByte(d.text[offset:], '\n')
+		if next < 0 {
+			return len(d.text)
+		}
+		offset += next + 1
+	}
+	for units := 0; units < pos.Character && offset < len(d.text) && d.text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		units += utf16Len(string(r))
+		offset += size
+	}
+	return offset
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
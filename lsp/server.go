@@ -0,0 +1,288 @@
+// Package lsp implements a Language Server Protocol server for clav.
+//
+// The server keeps every open document in memory, re-analysing it on each
+// change. Diagnostics come from the scanner and parser or, for programs
+// without errors, from the linter, whose resolver also answers hover,
+// definition, reference, symbol and completion requests. Formatting uses
+// the formatter.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/it-a-me/clavlang/format"
+	"github.com/it-a-me/clavlang/framing"
+	"github.com/it-a-me/clavlang/lint"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+type Server struct {
+	in   *bufio.Reader
+	out  io.Writer
+	docs map[string]*document
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, docs: map[string]*document{}}
+}
+
+// Run answers requests until the client sends exit or closes the input.
+func (s *Server) Run() error {
+	for {
+		data, err := framing.Read(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			err := &rpcError{Code: codeParseError, Message: err.Error()}
+			if err := framing.Write(s.out, errorResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(req)
+		if req.ID == nil {
+			// Notifications are never answered.
+			continue
+		}
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			err = framing.Write(s.out, errorResponse{JSONRPC: "2.0", ID: req.ID, Error: rpcErr})
+		} else {
+			err = framing.Write(s.out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "shutdown", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			return nil, s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publish(PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	case "textDocument/hover":
+		return withPosition(s, req, s.hover)
+	case "textDocument/definition":
+		return withPosition(s, req, s.definition)
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return s.references(doc, params.Position, params.Context.IncludeDeclaration), nil
+	case "textDocument/completion":
+		return withPosition(s, req, s.completion)
+	case "textDocument/documentSymbol":
+		return withDocument(s, req, s.documentSymbols)
+	case "textDocument/formatting":
+		return withDocument(s, req, s.formatting)
+	}
+	if req.ID == nil {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+}
+
+func decode(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func withPosition[T any](s *Server, req request, fn func(*document, Position) T) (any, error) {
+	var params TextDocumentPositionParams
+	if err := decode(req.Params, &params); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return fn(doc, params.Position), nil
+}
+
+func withDocument[T any](s *Server, req request, fn func(*document) T) (any, error) {
+	var params DocumentParams
+	if err := decode(req.Params, &params); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	return fn(doc), nil
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "document is not open: " + uri}
+	}
+	return doc, nil
+}
+
+func (s *Server) initialize() InitializeResult {
+	var result InitializeResult
+	result.ServerInfo.Name = "clav"
+	result.Capabilities = ServerCapabilities{
+		TextDocumentSync:           1,
+		HoverProvider:              true,
+		DefinitionProvider:         true,
+		ReferencesProvider:         true,
+		DocumentSymbolProvider:     true,
+		DocumentFormattingProvider: true,
+	}
+	return result
+}
+
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	return s.publish(PublishDiagnosticsParams{URI: uri, Diagnostics: doc.diagnostics()})
+}
+
+func (s *Server) publish(params PublishDiagnosticsParams) error {
+	return framing.Write(s.out, notification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+func (s *Server) hover(doc *document, pos Position) *Hover {
+	symbol, ok := doc.symbolAt(pos)
+	if !ok {
+		return nil
+	}
+	value := "```clav\n" + symbol.Detail + "\n```"
+	if symbol.Name.Line > 0 {
+		value += "\n\nDeclared on line " + strconv.Itoa(symbol.Name.Line)
+	}
+	tok := symbol.Name
+	offset := doc.offset(pos)
+	for _, use := range symbol.Uses {
+		if use.Offset <= offset && offset <= use.Offset+len(use.Lexeme) {
+			tok = use
+		}
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: value}, Range: doc.tokenRange(tok)}
+}
+
+func (s *Server) definition(doc *document, pos Position) *Location {
+	symbol, ok := doc.symbolAt(pos)
+	if !ok || symbol.Kind == lint.Builtin {
+		return nil
+	}
+	location := doc.location(symbol.Name)
+	return &location
+}
+
+func (s *Server) references(doc *document, pos Position, includeDeclaration bool) []Location {
+	locations := []Location{}
+	symbol, ok := doc.symbolAt(pos)
+	if !ok {
+		return locations
+	}
+	if includeDeclaration && symbol.Kind != lint.Builtin {
+		locations = append(locations, doc.location(symbol.Name))
+	}
+	for _, use := range symbol.Uses {
+		locations = append(locations, doc.location(use))
+	}
+	return locations
+}
+
+func (s *Server) completion(doc *document, pos Position) []CompletionItem {
+	analysis := lint.Analyze(doc.tokens, doc.stmts, doc.offset(pos))
+	// The innermost declaration of a name is the last one in the list.
+	kinds := map[string]*lint.Symbol{}
+	for _, symbol := range analysis.Symbols {
+		kinds[symbol.Name.Lexeme] = symbol
+	}
+	items := []CompletionItem{}
+	for _, name := range analysis.Visible {
+		item := CompletionItem{Label: name, Kind: CompletionVariable}
+		if symbol, ok := kinds[name]; ok {
+			item.Detail = symbol.Detail
+			switch symbol.Kind {
+			case lint.Function:
+				item.Kind = CompletionFunction
+			case lint.Builtin, lint.Import:
+				item.Kind = CompletionModule
+			case lint.Variable, lint.Parameter, lint.CatchVariable:
+			}
+		}
+		items = append(items, item)
+	}
+	for _, keyword := range scanner.KeywordNames() {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionKeyword})
+	}
+	return items
+}
+
+func (s *Server) documentSymbols(doc *document) []SymbolInformation {
+	symbols := []SymbolInformation{}
+	for _, symbol := range doc.analysis.Symbols {
+		var kind SymbolKind
+		switch symbol.Kind {
+		case lint.Variable:
+			kind = SymbolVariable
+		case lint.Function:
+			kind = SymbolFunction
+		case lint.Import:
+			kind = SymbolModule
+		case lint.Builtin, lint.Parameter, lint.CatchVariable:
+			continue
+		}
+		symbols = append(symbols, SymbolInformation{Name: symbol.Name.Lexeme, Kind: kind, Location: doc.location(symbol.Name)})
+	}
+	return symbols
+}
+
+// formatting replaces the whole document when it is not formatted. Files
+// that do not parse are left alone.
+func (s *Server) formatting(doc *document) []TextEdit {
+	out, err := format.Source([]byte(doc.text))
+	if err != nil || string(out) == doc.text {
+		return []TextEdit{}
+	}
+	whole := Range{End: doc.position(len(doc.text))}
+	return []TextEdit{{Range: whole, NewText: string(out)}}
+}
@@ -0,0 +1,285 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/lsp"
+)
+
+// client drives a Server running in the same process over a pair of pipes,
+// speaking JSON-RPC exactly like an editor would.
+type client struct {
+	t       *testing.T
+	in      io.WriteCloser
+	out     *bufio.Reader
+	nextID  int
+	done    chan error
+	notices []json.RawMessage
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, in: clientOut, out: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		c.done <- lsp.NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+	return c
+}
+
+func (c *client) send(message map[string]any) {
+	c.t.Helper()
+	message["jsonrpc"] = "2.0"
+	body, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// read returns the next message from the server.
+func (c *client) read() map[string]json.RawMessage {
+	c.t.Helper()
+	length := 0
+	for {
+		line, err := c.out.ReadString('\n')
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length: "); ok {
+			if length, err = strconv.Atoi(value); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.out, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		c.t.Fatal(err)
+	}
+	return message
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// request sends a request and returns its response, keeping the
+// notifications that arrive before it.
+func (c *client) request(method string, params any) map[string]json.RawMessage {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": method, "params": params})
+	for {
+		message := c.read()
+		if _, ok := message["id"]; ok {
+			return message
+		}
+		c.notices = append(c.notices, message["params"])
+	}
+}
+
+// call sends a request and decodes its result into result.
+func (c *client) call(method string, params, result any) {
+	c.t.Helper()
+	message := c.request(method, params)
+	if rpcErr, ok := message["error"]; ok {
+		c.t.Fatalf("%s failed: %s", method, rpcErr)
+	}
+	if err := json.Unmarshal(message["result"], result); err != nil {
+		c.t.Fatalf("%s: decode %s: %v", method, message["result"], err)
+	}
+}
+
+// diagnostics waits for the next diagnostics published for a document.
+func (c *client) diagnostics() lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+	var params lsp.PublishDiagnosticsParams
+	if len(c.notices) > 0 {
+		raw := c.notices[0]
+		c.notices = c.notices[1:]
+		if err := json.Unmarshal(raw, &params); err != nil {
+			c.t.Fatal(err)
+		}
+		return params
+	}
+	message := c.read()
+	if err := json.Unmarshal(message["params"], &params); err != nil {
+		c.t.Fatal(err)
+	}
+	return params
+}
+
+const uri = "file:///work/main.clav"
+
+const source = `var greeting = "hello";
+fun greet(name) {
+  var greeting = "hi";
+  return greeting + " " + name;
+}
+print greet("ann");
+print greeting + math.pi;
+`
+
+func position(line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+
+	var initialized lsp.InitializeResult
+	c.call("initialize", map[string]any{"capabilities": map[string]any{}}, &initialized)
+	if !initialized.Capabilities.HoverProvider || initialized.Capabilities.TextDocumentSync != 1 {
+		t.Errorf("unexpected capabilities %+v", initialized.Capabilities)
+	}
+	c.notify("initialized", map[string]any{})
+
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "clav", "version": 1, "text": source},
+	})
+	published := c.diagnostics()
+	if len(published.Diagnostics) != 1 || published.Diagnostics[0].Code != "shadow" || published.Diagnostics[0].Range.Start.Line != 2 {
+		t.Errorf("expected the shadowed greeting to be reported, got %+v", published.Diagnostics)
+	}
+
+	t.Run("hover", func(t *testing.T) {
+		var hover lsp.Hover
+		c.call("textDocument/hover", position(5, 7), &hover)
+		if !strings.Contains(hover.Contents.Value, "fun greet(name)") {
+			t.Errorf("hover on greet: %q", hover.Contents.Value)
+		}
+		if hover.Range.Start != (lsp.Position{Line: 5, Character: 6}) {
+			t.Errorf("hover range %+v", hover.Range)
+		}
+		c.call("textDocument/hover", position(6, 20), &hover)
+		if !strings.Contains(hover.Contents.Value, "standard library module math") {
+			t.Errorf("hover on math: %q", hover.Contents.Value)
+		}
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		// The greeting returned by greet is the local one on line 3.
+		var location lsp.Location
+		c.call("textDocument/definition", position(3, 10), &location)
+		if location.URI != uri || location.Range.Start != (lsp.Position{Line: 2, Character: 6}) {
+			t.Errorf("definition of local greeting: %+v", location)
+		}
+		c.call("textDocument/definition", position(6, 8), &location)
+		if location.Range.Start != (lsp.Position{Line: 0, Character: 4}) {
+			t.Errorf("definition of global greeting: %+v", location)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		var locations []lsp.Location
+		params := position(1, 11)
+		params["context"] = map[string]any{"includeDeclaration": true}
+		c.call("textDocument/references", params, &locations)
+		var lines []int
+		for _, l := range locations {
+			lines = append(lines, l.Range.Start.Line)
+		}
+		if !slices.Equal(lines, []int{1, 3}) {
+			t.Errorf("references to name on lines %v", lines)
+		}
+	})
+
+	t.Run("symbols", func(t *testing.T) {
+		var symbols []lsp.SymbolInformation
+		c.call("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}}, &symbols)
+		var names []string
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+		if !slices.Equal(names, []string{"greeting", "greet", "greeting"}) {
+			t.Errorf("symbols %v", names)
+		}
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		var items []lsp.CompletionItem
+		c.call("textDocument/completion", position(3, 2), &items)
+		labels := map[string]lsp.CompletionItemKind{}
+		for _, item := range items {
+			labels[item.Label] = item.Kind
+		}
+		for label, kind := range map[string]lsp.CompletionItemKind{
+			"name":   lsp.CompletionVariable,
+			"greet":  lsp.CompletionFunction,
+			"math":   lsp.CompletionModule,
+			"return": lsp.CompletionKeyword,
+		} {
+			if labels[label] != kind {
+				t.Errorf("completion %q has kind %d, want %d", label, labels[label], kind)
+			}
+		}
+		c.call("textDocument/completion", position(6, 0), &items)
+		for _, item := range items {
+			if item.Label == "name" {
+				t.Error("parameter name offered outside of its function")
+			}
+		}
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		var edits []lsp.TextEdit
+		c.call("textDocument/formatting", map[string]any{"textDocument": map[string]any{"uri": uri}}, &edits)
+		if len(edits) != 1 || !strings.Contains(edits[0].NewText, "\n    var greeting = \"hi\";\n") {
+			t.Errorf("formatting edits %+v", edits)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": 2},
+			"contentChanges": []map[string]any{{"text": "var x = ;\nprint \"é\" @;\n"}},
+		})
+		published := c.diagnostics()
+		var messages []string
+		for _, d := range published.Diagnostics {
+			messages = append(messages, fmt.Sprintf("%d:%d %s", d.Range.Start.Line, d.Range.Start.Character, d.Message))
+		}
+		want := []string{"1:0 Unexpected character '@'", "0:8 Expected Expression"}
+		if !slices.Equal(messages, want) {
+			t.Errorf("diagnostics %q, want %q", messages, want)
+		}
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		message := c.request("textDocument/rename", position(0, 4))
+		var rpcErr struct{ Code int }
+		if err := json.Unmarshal(message["error"], &rpcErr); err != nil || rpcErr.Code != -32601 {
+			t.Errorf("rename answered with %s", message["error"])
+		}
+	})
+
+	var nothing any
+	c.call("shutdown", nil, &nothing)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Fatalf("server stopped with %v", err)
+	}
+}
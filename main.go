@@ -2,51 +2,166 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/it-a-me/clavlang/interpreter"
 	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/pkg"
 	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/trace"
 )
 
-const (
-	MinArgs = 2
-	Verbose = false
-)
+// listFlag collects values from repeated or comma separated flags.
+type listFlag []string
+
+func (r *listFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *listFlag) Set(value string) error {
+	*r = append(*r, strings.Split(value, ",")...)
+	return nil
+}
 
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("-- ")
-	if len(os.Args) > MinArgs {
-		log.Fatal("Please supply 0-1 file arguments")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "pkg":
+			pkgCommand(os.Args[2:])
+			return
+		case "test":
+			testCommand(os.Args[2:])
+			return
+		case "fmt":
+			fmtCommand(os.Args[2:])
+			return
+		case "lint":
+			lintCommand(os.Args[2:])
+			return
+		case "lsp":
+			lspCommand(os.Args[2:])
+			return
+		case "debug":
+			debugCommand(os.Args[2:])
+			return
+		case "dap":
+			dapCommand(os.Args[2:])
+			return
+		case "run":
+			runCommand(os.Args[2:])
+			return
+		}
 	}
-	if len(os.Args) == MinArgs {
-		runFile(os.Args[1])
+
+	interpreterOptions := interpreterFlags(flag.CommandLine)
+	traced := traceFlags(flag.CommandLine)
+	flag.Parse()
+	opts := interpreterOptions()
+
+	// Arguments after the script are passed on to it as os.args.
+	if flag.NArg() > 0 {
+		exitOnError(runFile(flag.Arg(0), *traced, append(opts, interpreter.WithArgs(flag.Args()[1:]))))
 	} else {
-		repl()
+		repl(*traced, opts)
 	}
 }
 
-func repl() {
+// interpreterFlags registers the flags configuring an Interpreter on flags.
+// The returned function builds the options once flags have been parsed.
+func interpreterFlags(flags *flag.FlagSet) func() []interpreter.Option {
+	var capabilities interpreter.Capabilities
+	flags.Var((*listFlag)(&capabilities.ReadRoots), "allow-read", "allow reading files below `dirs`")
+	flags.Var((*listFlag)(&capabilities.WriteRoots), "allow-write", "allow writing files below `dirs`")
+	flags.BoolVar(&capabilities.Env, "allow-env", false, "allow access to environment variables")
+	flags.BoolVar(&capabilities.Time, "allow-time", false, "allow access to the clock")
+	flags.BoolVar(&capabilities.Exec, "allow-exec", false, "allow running subprocesses")
+	allowAll := flags.Bool("allow-all", false, "grant every capability")
+	var searchPath listFlag
+	flags.Var(&searchPath, "module-path", "search `dirs` for imported modules")
+	seed := flags.Uint64("seed", 0, "seed the random module for reproducible runs")
+	return func() []interpreter.Option {
+		if *allowAll {
+			capabilities = interpreter.AllCapabilities()
+		}
+		opts := []interpreter.Option{
+			interpreter.WithCapabilities(capabilities),
+			interpreter.WithSearchPath(searchPath...),
+		}
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "seed" {
+				opts = append(opts, interpreter.WithSeed(*seed))
+			}
+		})
+		return opts
+	}
+}
+
+// traceOptions selects what run reports on stderr about a script.
+type traceOptions struct {
+	tokens, ast, exec, json bool
+}
+
+// traceFlags registers the flags tracing a run on flags.
+func traceFlags(flags *flag.FlagSet) *traceOptions {
+	var t traceOptions
+	flags.BoolVar(&t.tokens, "trace-tokens", false, "print the tokens of the script")
+	flags.BoolVar(&t.ast, "trace-ast", false, "print the syntax tree of the script")
+	flags.BoolVar(&t.exec, "trace-exec", false, "print every statement, expression value and scope as the script runs")
+	flags.BoolVar(&t.json, "trace-json", false, "print traces as JSON lines")
+	return &t
+}
+
+// walkSources calls fn for root or, when root is a directory, for every
+// .clav file below it outside of vendored packages.
+func walkSources(root string, fn func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == pkg.VendorDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".clav" && path != root {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+func repl(traced traceOptions, opts []interpreter.Option) {
 	reader := bufio.NewReader(os.Stdin)
 	text, err := reader.ReadString('\n')
 	for err == nil {
-		run(text)
+		exitOnError(run(text, traced, opts...))
 		text, err = reader.ReadString('\n')
 	}
 	log.Fatal(err)
 }
 
-func runFile(path string) {
+func runFile(path string, traced traceOptions, opts []interpreter.Option) error {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
 		log.Fatal(err)
 	}
-	run(string(bytes))
+	opts = append(opts, packageOptions(path)...)
+	return run(string(bytes), traced, append(opts, interpreter.WithScriptPath(path))...)
 }
 
-func run(text string) {
+// run executes text, exiting when it does not scan or parse, and returns
+// the error the program stopped with.
+func run(text string, traced traceOptions, opts ...interpreter.Option) error {
+	tracer := trace.New(os.Stderr, traced.json)
 	s := scanner.NewScanner(text)
 
 	tokens, errs := s.Scan()
@@ -56,12 +171,8 @@ func run(text string) {
 		}
 		os.Exit(1)
 	}
-	if Verbose {
-		log.Print("[")
-		for _, t := range tokens {
-			log.Printf(" %s", t.Type.String())
-		}
-		log.Println("]")
+	if traced.tokens {
+		tracer.Tokens(tokens)
 	}
 
 	p := parser.NewParser(tokens)
@@ -72,13 +183,25 @@ func run(text string) {
 		}
 		os.Exit(1)
 	}
-	if Verbose {
-		for _, s := range expr {
-			log.Println(parser.LispStmt(s))
-		}
+	if traced.ast {
+		tracer.AST(expr)
 	}
-	inter := interpreter.NewInterpreter()
-	if err := inter.Interpret(expr); err != nil {
-		log.Fatal(err)
+	if traced.exec {
+		opts = append(opts, interpreter.WithTracer(tracer))
 	}
+	inter := interpreter.NewInterpreter(opts...)
+	return inter.Interpret(expr)
+}
+
+// exitOnError exits with the status a program asked for or reports the
+// error it failed with.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	var exit interpreter.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.Code)
+	}
+	log.Fatal(err)
 }
@@ -2,7 +2,8 @@ package parser
 
 import (
 	"fmt"
-	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/it-a-me/clavlang/token"
 	"github.com/it-a-me/clavlang/types"
@@ -23,6 +24,7 @@ type Grouping struct {
 }
 
 type Literal struct {
+	Token token.Token
 	Value types.ClavType
 }
 
@@ -40,44 +42,148 @@ type Assign struct {
 	Value Expr
 }
 
+type Call struct {
+	Callee    Expr
+	Paren     token.Token
+	Arguments []Expr
+}
+
+type ListLiteral struct {
+	Bracket  token.Token
+	Elements []Expr
+}
+
+type Get struct {
+	Object Expr
+	Name   token.Token
+}
+
+// LispStmt renders stmt as an s-expression, for tracing and debugging the
+// parser.
 func LispStmt(stmt Stmt) string {
 	switch s := stmt.(type) {
 	case Print:
 		return "(print " + LispExpr(s.Inner) + ")"
 	case Expression:
 		return LispExpr(s.Inner)
+	case Var:
+		if s.Initializer == nil {
+			return "(var " + s.Name.Lexeme + ")"
+		}
+		return "(var " + s.Name.Lexeme + " " + LispExpr(s.Initializer) + ")"
+	case Block:
+		return lisp("block", lispStmts(s.Statements)...)
+	case Try:
+		handler := lisp("catch "+s.Name.Lexeme, lispStmts(s.Handler)...)
+		return lisp("try", lisp("block", lispStmts(s.Body)...), handler)
+	case Function:
+		params := make([]string, len(s.Params))
+		for j, param := range s.Params {
+			params[j] = param.Lexeme
+		}
+		body := append([]string{"(" + strings.Join(params, " ") + ")"}, lispStmts(s.Body)...)
+		return lisp("fun "+s.Name.Lexeme, body...)
+	case Return:
+		if s.Value == nil {
+			return "(return)"
+		}
+		return "(return " + LispExpr(s.Value) + ")"
+	case Import:
+		if len(s.Names) > 0 {
+			names := make([]string, len(s.Names))
+			for j, name := range s.Names {
+				names[j] = name.Lexeme
+			}
+			return lisp("from "+s.Path.Lexeme+" import", names...)
+		}
+		if s.Alias.Lexeme != "" {
+			return "(import " + s.Path.Lexeme + " " + s.Alias.Lexeme + ")"
+		}
+		return "(import " + s.Path.Lexeme + ")"
 	}
 	panic("Unreachable")
 }
 
+// LispExpr renders expr as an s-expression with the operator first.
 func LispExpr(expr Expr) string {
-	if l, ok := expr.(Literal); ok {
-		return fmt.Sprintf("%v", l.Value)
+	switch e := expr.(type) {
+	case Literal:
+		if s, ok := e.Value.(types.String); ok {
+			return strconv.Quote(s.Value)
+		}
+		return fmt.Sprintf("%v", e.Value)
+	case Grouping:
+		return "(group " + LispExpr(e.Expression) + ")"
+	case Unary:
+		return "(" + e.Operator.Lexeme + " " + LispExpr(e.Right) + ")"
+	case Binary:
+		return "(" + e.Operator.Lexeme + " " + LispExpr(e.Left) + " " + LispExpr(e.Right) + ")"
+	case Variable:
+		return e.Name.Lexeme
+	case Assign:
+		return "(= " + e.Name.Lexeme + " " + LispExpr(e.Value) + ")"
+	case Call:
+		return lisp("call", lispExprs(append([]Expr{e.Callee}, e.Arguments...))...)
+	case Get:
+		return "(. " + LispExpr(e.Object) + " " + e.Name.Lexeme + ")"
+	case ListLiteral:
+		return lisp("list", lispExprs(e.Elements)...)
 	}
+	panic("Unreachable")
+}
 
-	value := reflect.ValueOf(expr)
-	s := "("
-	for i := range value.NumField() {
-		f := value.Field(i)
-		if i != 0 {
-			s += " "
-		}
-		switch v := f.Interface().(type) {
-		case Expr:
-			s += LispExpr(v)
-		case token.Token:
-			s += v.Lexeme
-		default:
-			s += f.String()
-		}
+func lisp(head string, items ...string) string {
+	return "(" + strings.Join(append([]string{head}, items...), " ") + ")"
+}
+
+func lispStmts(stmts []Stmt) []string {
+	items := make([]string, len(stmts))
+	for j, stmt := range stmts {
+		items[j] = LispStmt(stmt)
+	}
+	return items
+}
+
+func lispExprs(exprs []Expr) []string {
+	items := make([]string, len(exprs))
+	for j, expr := range exprs {
+		items[j] = LispExpr(expr)
+	}
+	return items
+}
+
+// ExprToken returns the first token of an expression or, where the tree
+// does not keep it, the first one it does keep.
+func ExprToken(expr Expr) token.Token {
+	switch e := expr.(type) {
+	case Binary:
+		return ExprToken(e.Left)
+	case Grouping:
+		return ExprToken(e.Expression)
+	case Literal:
+		return e.Token
+	case Unary:
+		return e.Operator
+	case Variable:
+		return e.Name
+	case Assign:
+		return e.Name
+	case Call:
+		return ExprToken(e.Callee)
+	case Get:
+		return ExprToken(e.Object)
+	case ListLiteral:
+		return e.Bracket
 	}
-	s += ")"
-	return s
+	return token.Token{}
 }
 
-func (Binary) expr()   {}
-func (Grouping) expr() {}
-func (Literal) expr()  {}
-func (Unary) expr()    {}
-func (Variable) expr() {}
-func (Assign) expr() {}
+func (Binary) expr()      {}
+func (Grouping) expr()    {}
+func (Literal) expr()     {}
+func (Unary) expr()       {}
+func (Variable) expr()    {}
+func (Assign) expr()      {}
+func (Call) expr()        {}
+func (Get) expr()         {}
+func (ListLiteral) expr() {}
@@ -0,0 +1,67 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// FuzzParse feeds the parser arbitrary token streams, including ones the
+// scanner never produces such as streams without a trailing EOF. Each byte
+// of the input selects the type of one token.
+func FuzzParse(f *testing.F) {
+	for _, source := range []string{
+		`var x = 1; print x;`,
+		`fun add(a, b) { return a + b; } print add(1, 2);`,
+		`try { [1].get(2); } catch (e) { print e; }`,
+		`from "lib.clav" import a, b; import "other.clav" as o;`,
+		`print -"a".length() == !true;`,
+		``,
+	} {
+		f.Add(encode(f, source))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := parser.NewParser(decode(data))
+		stmts, errs := p.Parse()
+		for _, stmt := range stmts {
+			if stmt == nil {
+				t.Fatalf("Parse returned a nil statement alongside %d errors", len(errs))
+			}
+		}
+	})
+}
+
+func encode(f *testing.F, source string) []byte {
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		f.Fatalf("scan %q: %v", source, errs)
+	}
+	data := make([]byte, len(tokens))
+	for i, tok := range tokens {
+		data[i] = byte(tok.Type)
+	}
+	return data
+}
+
+func decode(data []byte) []token.Token {
+	tokens := make([]token.Token, len(data))
+	for i, b := range data {
+		t := token.Type(int(b) % int(token.EOF+1))
+		var literal types.ClavType
+		lexeme := t.String()
+		switch t {
+		case token.Identifier:
+			lexeme = "x"
+		case token.Number:
+			literal = types.Number{Value: float64(i)}
+		case token.String:
+			literal = types.String{Value: "s"}
+		}
+		tokens[i] = token.NewToken(t, lexeme, literal, i/8+1)
+	}
+	return tokens
+}
@@ -0,0 +1,40 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+func TestLisp(t *testing.T) {
+	for source, want := range map[string]string{
+		`print 1 + 2 * -x;`:                            `(print (+ 1 (* 2 (- x))))`,
+		`var s = "a" + (b);`:                           `(var s (+ "a" (group b)))`,
+		`var n;`:                                       `(var n)`,
+		`x = list.get(0);`:                             `(= x (call (. list get) 0))`,
+		`print [1, nil, true];`:                        `(print (list 1 nil true))`,
+		`{ var a = 1; print a; }`:                      `(block (var a 1) (print a))`,
+		`fun add(a, b) { return a + b; }`:              `(fun add (a b) (return (+ a b)))`,
+		`fun f() { return; }`:                          `(fun f () (return))`,
+		`try { f(); } catch (e) { print e; }`:          `(try (block (call f)) (catch e (print e)))`,
+		`import "lib.clav";`:                           `(import "lib.clav")`,
+		`import "lib.clav" as l;`:                      `(import "lib.clav" l)`,
+		`from "lib.clav" import a, b;`:                 `(from "lib.clav" import a b)`,
+		`fun g(a) { try { return a; } catch (e) { } }`: `(fun g (a) (try (block (return a)) (catch e)))`,
+	} {
+		s := scanner.NewScanner(source)
+		tokens, errs := s.Scan()
+		if errs != nil {
+			t.Fatalf("scan %q: %v", source, errs)
+		}
+		p := parser.NewParser(tokens)
+		stmts, errs := p.Parse()
+		if errs != nil || len(stmts) != 1 {
+			t.Fatalf("parse %q: %v", source, errs)
+		}
+		if got := parser.LispStmt(stmts[0]); got != want {
+			t.Errorf("LispStmt(%q) = %s, want %s", source, got, want)
+		}
+	}
+}
@@ -29,11 +29,20 @@ func (p *Parser) Parse() ([]Stmt, []error) {
 }
 
 func (p *Parser) declaration() (Stmt, error) {
-	if p.match(token.Var) {
-		return p.varDeclaration()
+	var stmt Stmt
+	var err error
+	switch {
+	case p.match(token.Var):
+		stmt, err = p.varDeclaration()
+	case p.match(token.Fun):
+		stmt, err = p.function()
+	case p.match(token.Import):
+		stmt, err = p.importStatement()
+	case p.match(token.From):
+		stmt, err = p.fromImportStatement()
+	default:
+		stmt, err = p.statement()
 	}
-
-	stmt, err := p.statement()
 	if err != nil {
 		p.synchronize()
 		return nil, err
@@ -41,6 +50,84 @@ func (p *Parser) declaration() (Stmt, error) {
 	return stmt, nil
 }
 
+func (p *Parser) function() (Stmt, error) {
+	name, err := p.consume(token.Identifier, "Expect function name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LeftParen, "Expect '(' after function name"); err != nil {
+		return nil, err
+	}
+	params := []token.Token{}
+	if !p.check(token.RightParen) {
+		for {
+			param, err := p.consume(token.Identifier, "Expect parameter name")
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, param)
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(token.RightParen, "Expect ')' after parameters"); err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LeftBrace, "Expect '{' before function body"); err != nil {
+		return nil, err
+	}
+	body, err := p.blockStatements()
+	if err != nil {
+		return nil, err
+	}
+	return Function{Name: name, Params: params, Body: body}, nil
+}
+
+func (p *Parser) importStatement() (Stmt, error) {
+	keyword := p.previous()
+	path, err := p.consume(token.String, "Expect module path after 'import'")
+	if err != nil {
+		return nil, err
+	}
+	var alias token.Token
+	if p.match(token.As) {
+		if alias, err = p.consume(token.Identifier, "Expect module name after 'as'"); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.consume(token.Semicolon, "Expect ';' after import"); err != nil {
+		return nil, err
+	}
+	return Import{Keyword: keyword, Path: path, Alias: alias}, nil
+}
+
+func (p *Parser) fromImportStatement() (Stmt, error) {
+	keyword := p.previous()
+	path, err := p.consume(token.String, "Expect module path after 'from'")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.Import, "Expect 'import' after module path"); err != nil {
+		return nil, err
+	}
+	names := []token.Token{}
+	for {
+		name, err := p.consume(token.Identifier, "Expect name to import")
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.match(token.Comma) {
+			break
+		}
+	}
+	if _, err := p.consume(token.Semicolon, "Expect ';' after import"); err != nil {
+		return nil, err
+	}
+	return Import{Keyword: keyword, Path: path, Names: names}, nil
+}
+
 func (p *Parser) varDeclaration() (Stmt, error) {
 	name, err := p.consume(token.Identifier, "Expect variable name")
 	if err != nil {
@@ -72,7 +159,7 @@ func (p *Parser) assignment() (Expr, error) {
 			name := v.Name
 			return Assign{name, value}, nil
 		}
-		p.newError("Invalid assignment target")
+		return nil, p.newError("Invalid assignment target")
 	}
 	return expr, nil
 }
@@ -84,10 +171,65 @@ func (p *Parser) statement() (Stmt, error) {
 	if p.match(token.LeftBrace) {
 		return p.block()
 	}
+	if p.match(token.Try) {
+		return p.tryStatement()
+	}
+	if p.match(token.Return) {
+		return p.returnStatement()
+	}
 	return p.expressionStatement()
 }
 
+func (p *Parser) returnStatement() (Stmt, error) {
+	keyword := p.previous()
+	var value Expr
+	if !p.check(token.Semicolon) {
+		var err error
+		value, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.consume(token.Semicolon, "Expect ';' after return value"); err != nil {
+		return nil, err
+	}
+	return Return{Keyword: keyword, Value: value}, nil
+}
+
+func (p *Parser) tryStatement() (Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LeftBrace, "Expect '{' after 'try'"); err != nil {
+		return nil, err
+	}
+	body, err := p.blockStatements()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.Catch, "Expect 'catch' after try block"); err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LeftParen, "Expect '(' after 'catch'"); err != nil {
+		return nil, err
+	}
+	name, err := p.consume(token.Identifier, "Expect error variable name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.RightParen, "Expect ')' after error variable"); err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LeftBrace, "Expect '{' before catch block"); err != nil {
+		return nil, err
+	}
+	handler, err := p.blockStatements()
+	if err != nil {
+		return nil, err
+	}
+	return Try{Keyword: keyword, Body: body, Name: name, Handler: handler}, nil
+}
+
 func (p *Parser) printStatement() (Stmt, error) {
+	keyword := p.previous()
 	value, err := p.expression()
 	if err != nil {
 		return nil, err
@@ -95,7 +237,7 @@ func (p *Parser) printStatement() (Stmt, error) {
 	if _, err := p.consume(token.Semicolon, "Expect ';' after value"); err != nil {
 		return nil, err
 	}
-	return Print{Inner: value}, nil
+	return Print{Keyword: keyword, Inner: value}, nil
 }
 
 func (p *Parser) expressionStatement() (Stmt, error) {
@@ -110,6 +252,15 @@ func (p *Parser) expressionStatement() (Stmt, error) {
 }
 
 func (p *Parser) block() (Stmt, error) {
+	brace := p.previous()
+	statements, err := p.blockStatements()
+	if err != nil {
+		return nil, err
+	}
+	return Block{Brace: brace, Statements: statements}, nil
+}
+
+func (p *Parser) blockStatements() ([]Stmt, error) {
 	statements := []Stmt{}
 	for !p.check(token.RightBrace) && !p.isAtEnd() {
 		decl, err := p.declaration()
@@ -119,7 +270,7 @@ func (p *Parser) block() (Stmt, error) {
 		statements = append(statements, decl)
 	}
 	p.consume(token.RightBrace, "Expect '}' after block")
-	return Block{statements}, nil
+	return statements, nil
 }
 
 func (p *Parser) expression() (Expr, error) {
@@ -204,19 +355,64 @@ func (p *Parser) unary() (Expr, error) {
 		}
 		return Expr(Unary{Operator: operator, Right: right}), nil
 	}
-	return p.primary()
+	return p.call()
+}
+
+func (p *Parser) call() (Expr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.match(token.LeftParen):
+			expr, err = p.finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		case p.match(token.Dot):
+			name, err := p.consume(token.Identifier, "Expect property name after '.'")
+			if err != nil {
+				return nil, err
+			}
+			expr = Expr(Get{Object: expr, Name: name})
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *Parser) finishCall(callee Expr) (Expr, error) {
+	arguments := []Expr{}
+	if !p.check(token.RightParen) {
+		for {
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, arg)
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+	paren, err := p.consume(token.RightParen, "Expect ')' after arguments")
+	if err != nil {
+		return nil, err
+	}
+	return Expr(Call{Callee: callee, Paren: paren, Arguments: arguments}), nil
 }
 
 func (p *Parser) primary() (Expr, error) {
 	switch {
 	case p.match(token.False):
-		return Expr(Literal{Value: types.Boolean{Value: false}}), nil
+		return Expr(Literal{Token: p.previous(), Value: types.Boolean{Value: false}}), nil
 	case p.match(token.True):
-		return Expr(Literal{Value: types.Boolean{Value: true}}), nil
+		return Expr(Literal{Token: p.previous(), Value: types.Boolean{Value: true}}), nil
 	case p.match(token.Nil):
-		return Expr(Literal{Value: nil}), nil
+		return Expr(Literal{Token: p.previous(), Value: types.Nil{}}), nil
 	case p.match(token.Number, token.String):
-		return Expr(Literal{Value: p.previous().Literal}), nil
+		return Expr(Literal{Token: p.previous(), Value: p.previous().Literal}), nil
 	case p.match(token.LeftParen):
 		expr, err := p.expression()
 		if err != nil {
@@ -229,10 +425,33 @@ func (p *Parser) primary() (Expr, error) {
 		return Expr(Grouping{Expression: expr}), nil
 	case p.match(token.Identifier):
 		return Variable{p.previous()}, nil
+	case p.match(token.LeftBracket):
+		return p.listLiteral()
 	}
 	return nil, p.newError("Expected Expression")
 }
 
+func (p *Parser) listLiteral() (Expr, error) {
+	bracket := p.previous()
+	elements := []Expr{}
+	if !p.check(token.RightBracket) {
+		for {
+			element, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+			if !p.match(token.Comma) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(token.RightBracket, "Expect ']' after list elements"); err != nil {
+		return nil, err
+	}
+	return Expr(ListLiteral{Bracket: bracket, Elements: elements}), nil
+}
+
 func (p *Parser) synchronize() {
 	p.advance()
 
@@ -256,6 +475,12 @@ func (p *Parser) synchronize() {
 			fallthrough
 		case token.Print:
 			fallthrough
+		case token.Try:
+			fallthrough
+		case token.Import:
+			fallthrough
+		case token.From:
+			fallthrough
 		case token.Return:
 			return
 		default:
@@ -301,11 +526,22 @@ func (p *Parser) advance() token.Token {
 	return p.previous()
 }
 
+// peek treats a stream that ends without an EOF token as if it had one.
 func (p *Parser) peek() token.Token {
+	if p.current >= len(p.tokens) {
+		line := 1
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].Line
+		}
+		return token.NewToken(token.EOF, "", nil, line)
+	}
 	return p.tokens[p.current]
 }
 
 func (p *Parser) previous() token.Token {
+	if p.current == 0 {
+		return p.peek()
+	}
 	return p.tokens[p.current-1]
 }
 
@@ -7,6 +7,7 @@ type Stmt interface {
 }
 
 type Block struct {
+	Brace      token.Token
 	Statements []Stmt
 }
 
@@ -15,7 +16,8 @@ type Expression struct {
 }
 
 type Print struct {
-	Inner Expr
+	Keyword token.Token
+	Inner   Expr
 }
 
 type Var struct {
@@ -23,7 +25,64 @@ type Var struct {
 	Initializer Expr
 }
 
+// Try runs Body and, if it fails with a runtime error, runs Handler with
+// the error message bound to Name.
+type Try struct {
+	Keyword token.Token
+	Body    []Stmt
+	Name    token.Token
+	Handler []Stmt
+}
+
+type Function struct {
+	Name   token.Token
+	Params []token.Token
+	Body   []Stmt
+}
+
+type Return struct {
+	Keyword token.Token
+	Value   Expr
+}
+
+// Import loads the module at Path. Either the module is bound to Alias or,
+// for `from "path" import a, b;`, each of Names is bound directly.
+type Import struct {
+	Keyword token.Token
+	Path    token.Token
+	Alias   token.Token
+	Names   []token.Token
+}
+
+// StmtToken returns the first token of a statement or, where the tree
+// does not keep it, the first one it does keep.
+func StmtToken(stmt Stmt) token.Token {
+	switch s := stmt.(type) {
+	case Block:
+		return s.Brace
+	case Expression:
+		return ExprToken(s.Inner)
+	case Print:
+		return s.Keyword
+	case Var:
+		return s.Name
+	case Try:
+		return s.Keyword
+	case Function:
+		return s.Name
+	case Return:
+		return s.Keyword
+	case Import:
+		return s.Keyword
+	}
+	return token.Token{}
+}
+
 func (Block) stmt()      {}
 func (Expression) stmt() {}
 func (Print) stmt()      {}
 func (Var) stmt()        {}
+func (Try) stmt()        {}
+func (Function) stmt()   {}
+func (Return) stmt()     {}
+func (Import) stmt()     {}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Locked is a resolved package as recorded in the lockfile. Source is
+// either "path+<dir>", relative to the project, or "registry+<version>".
+type Locked struct {
+	Name    string
+	Version string
+	Source  string
+	Hash    string
+}
+
+type Lockfile struct {
+	Packages []Locked
+}
+
+func ReadLockfile(dir string) (Lockfile, error) {
+	source, err := os.ReadFile(filepath.Join(dir, LockFile))
+	if err != nil {
+		return Lockfile{}, err
+	}
+	doc, err := parseTOML(string(source))
+	if err != nil {
+		return Lockfile{}, fmt.Errorf("%s: %w", filepath.Join(dir, LockFile), err)
+	}
+	var lock Lockfile
+	packages, _ := doc["package"].([]table)
+	for _, p := range packages {
+		locked := Locked{
+			Name:    p.string("name"),
+			Version: p.string("version"),
+			Source:  p.string("source"),
+			Hash:    p.string("hash"),
+		}
+		if err := locked.check(); err != nil {
+			return Lockfile{}, fmt.Errorf("%s: %w", filepath.Join(dir, LockFile), err)
+		}
+		lock.Packages = append(lock.Packages, locked)
+	}
+	return lock, nil
+}
+
+// check rejects a package whose name or versions could lead outside of the
+// vendor directory or the registry.
+func (p Locked) check() error {
+	if err := checkNameAndVersion(p.Name, p.Version); err != nil {
+		return err
+	}
+	if version, ok := strings.CutPrefix(p.Source, "registry+"); ok && !validComponent(version) {
+		return fmt.Errorf("package %s has invalid source %q", p.Name, p.Source)
+	}
+	return nil
+}
+
+func (l Lockfile) Write(dir string) error {
+	var b strings.Builder
+	b.WriteString("# This file is generated by `clav pkg lock`. Do not edit.\n")
+	for _, p := range l.Packages {
+		b.WriteString("\n[[package]]\n")
+		fmt.Fprintf(&b, "name = %s\n", quote(p.Name))
+		fmt.Fprintf(&b, "version = %s\n", quote(p.Version))
+		fmt.Fprintf(&b, "source = %s\n", quote(p.Source))
+		fmt.Fprintf(&b, "hash = %s\n", quote(p.Hash))
+	}
+	return os.WriteFile(filepath.Join(dir, LockFile), []byte(b.String()), filePerm)
+}
+
+// Packages maps each locked package name to its vendored directory, for use
+// by the import resolver.
+func Packages(project string) (map[string]string, error) {
+	lock, err := ReadLockfile(project)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]string, len(lock.Packages))
+	for _, p := range lock.Packages {
+		dirs[p.Name] = filepath.Join(project, VendorDir, p.Name)
+	}
+	return dirs, nil
+}
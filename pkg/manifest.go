@@ -0,0 +1,142 @@
+// Package pkg implements clav's local package manager. A project describes
+// its dependencies in a clav.toml manifest:
+//
+//	[package]
+//	name = "app"
+//	version = "0.1.0"
+//
+//	[registry]
+//	path = "../registry"
+//
+//	[dependencies]
+//	util = { path = "../util" }
+//	strings = { version = "1.2.0" }
+//
+// Dependencies come either from a local directory or from a file based
+// registry laid out as <registry>/<name>/<version>/. Resolved packages are
+// recorded with content hashes in clav.lock and vendored into clav_modules.
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	ManifestFile = "clav.toml"
+	LockFile     = "clav.lock"
+	VendorDir    = "clav_modules"
+)
+
+type Manifest struct {
+	Name         string
+	Version      string
+	Registry     string
+	Dependencies []Dependency
+}
+
+// Dependency is a requirement on another package, satisfied either by the
+// directory Path or by Version from the registry.
+type Dependency struct {
+	Name    string
+	Path    string
+	Version string
+}
+
+// ReadManifest reads the manifest in dir. Relative paths in it are resolved
+// against dir.
+func ReadManifest(dir string) (Manifest, error) {
+	source, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Manifest{}, err
+	}
+	doc, err := parseTOML(string(source))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	var m Manifest
+	if info, ok := doc["package"].(table); ok {
+		m.Name = info.string("name")
+		m.Version = info.string("version")
+	}
+	if m.Name == "" {
+		return Manifest{}, fmt.Errorf("%s: missing package name", filepath.Join(dir, ManifestFile))
+	}
+	if err := checkNameAndVersion(m.Name, m.Version); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+	}
+	if registry, ok := doc["registry"].(table); ok && registry.string("path") != "" {
+		m.Registry = resolvePath(dir, registry.string("path"))
+	}
+	deps, _ := doc["dependencies"].(table)
+	for name, spec := range deps {
+		dep := Dependency{Name: name}
+		switch s := spec.(type) {
+		case string:
+			dep.Version = s
+		case table:
+			dep.Version = s.string("version")
+			if s.string("path") != "" {
+				dep.Path = resolvePath(dir, s.string("path"))
+			}
+		}
+		if (dep.Path == "") == (dep.Version == "") {
+			return Manifest{}, errors.New("dependency " + name + " needs exactly one of path or version")
+		}
+		if err := checkNameAndVersion(dep.Name, dep.Version); err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+		}
+		m.Dependencies = append(m.Dependencies, dep)
+	}
+	sort.Slice(m.Dependencies, func(a, b int) bool {
+		return m.Dependencies[a].Name < m.Dependencies[b].Name
+	})
+	return m, nil
+}
+
+// FindProject returns the closest directory at or above dir that holds a
+// manifest.
+func FindProject(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// checkNameAndVersion rejects a package name or version, which may be
+// empty, that is not usable as a single directory name. Both are joined to
+// the vendor directory and the registry, so they must not lead out of them.
+func checkNameAndVersion(name, version string) error {
+	if !validComponent(name) {
+		return fmt.Errorf("invalid package name %q", name)
+	}
+	if version != "" && !validComponent(version) {
+		return fmt.Errorf("package %s has invalid version %q", name, version)
+	}
+	return nil
+}
+
+func validComponent(s string) bool {
+	return s != "" && s != "." && !strings.Contains(s, "..") && !strings.ContainsAny(s, `/\`)
+}
@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// Resolve walks the dependency graph of the project in dir and returns the
+// lockfile describing it. registry overrides the manifest's registry when
+// it is not empty.
+func Resolve(dir, registry string) (Lockfile, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Lockfile{}, err
+	}
+	root, err := ReadManifest(dir)
+	if err != nil {
+		return Lockfile{}, err
+	}
+	if registry == "" {
+		registry = root.Registry
+	}
+	r := resolver{project: dir, registry: registry, resolved: map[string]Locked{}}
+	if err := r.resolve(root, []string{root.Name}); err != nil {
+		return Lockfile{}, err
+	}
+	var lock Lockfile
+	for _, p := range r.resolved {
+		lock.Packages = append(lock.Packages, p)
+	}
+	sort.Slice(lock.Packages, func(a, b int) bool { return lock.Packages[a].Name < lock.Packages[b].Name })
+	return lock, nil
+}
+
+type resolver struct {
+	project  string
+	registry string
+	resolved map[string]Locked
+}
+
+// resolve locks every dependency of m. chain is the path of package names
+// from the project, used to report cycles.
+func (r *resolver) resolve(m Manifest, chain []string) error {
+	for _, dep := range m.Dependencies {
+		if i := slices.Index(chain, dep.Name); i >= 0 {
+			return errors.New("dependency cycle: " + strings.Join(append(chain[i:], dep.Name), " -> "))
+		}
+		dir, source, err := r.locate(dep)
+		if err != nil {
+			return err
+		}
+		manifest, err := ReadManifest(dir)
+		if err != nil {
+			return fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+		if manifest.Name != dep.Name {
+			return fmt.Errorf("dependency %s: manifest declares package %s", dep.Name, manifest.Name)
+		}
+		if existing, ok := r.resolved[dep.Name]; ok {
+			if existing.Source != source {
+				return fmt.Errorf("conflicting requirements for %s: %s and %s", dep.Name, existing.Source, source)
+			}
+			continue
+		}
+		hash, err := HashDir(dir)
+		if err != nil {
+			return err
+		}
+		r.resolved[dep.Name] = Locked{Name: dep.Name, Version: manifest.Version, Source: source, Hash: hash}
+		if err := r.resolve(manifest, append(chain, dep.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locate returns the directory holding dep and its lockfile source.
+func (r *resolver) locate(dep Dependency) (string, string, error) {
+	if dep.Path != "" {
+		rel, err := filepath.Rel(r.project, dep.Path)
+		if err != nil {
+			rel = dep.Path
+		}
+		return dep.Path, "path+" + filepath.ToSlash(rel), nil
+	}
+	if r.registry == "" {
+		return "", "", fmt.Errorf("dependency %s@%s needs a registry", dep.Name, dep.Version)
+	}
+	dir := filepath.Join(r.registry, dep.Name, dep.Version)
+	if _, err := os.Stat(dir); err != nil {
+		return "", "", fmt.Errorf("dependency %s@%s not found in registry %s", dep.Name, dep.Version, r.registry)
+	}
+	return dir, "registry+" + dep.Version, nil
+}
+
+// sourceDir is the inverse of locate for a locked package.
+func sourceDir(project, registry string, p Locked) (string, error) {
+	if rel, ok := strings.CutPrefix(p.Source, "path+"); ok {
+		return resolvePath(project, filepath.FromSlash(rel)), nil
+	}
+	if version, ok := strings.CutPrefix(p.Source, "registry+"); ok {
+		if registry == "" {
+			return "", fmt.Errorf("package %s needs a registry", p.Name)
+		}
+		return filepath.Join(registry, p.Name, version), nil
+	}
+	return "", fmt.Errorf("package %s has unknown source %q", p.Name, p.Source)
+}
+
+// HashDir hashes the relative paths and contents of every file below dir,
+// ignoring vendored dependencies and lockfiles.
+func HashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == VendorDir {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() && d.Name() != LockFile {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
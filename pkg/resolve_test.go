@@ -0,0 +1,154 @@
+package pkg_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/pkg"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func manifest(name, version, deps string) string {
+	return "[package]\nname = \"" + name + "\"\nversion = \"" + version + "\"\n\n[dependencies]\n" + deps
+}
+
+func TestLockAndVendor(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/clav.toml":                 manifest("app", "0.1.0", "util = { path = \"../util\" }\nstrs = \"1.0.0\"\n"),
+		"util/clav.toml":                manifest("util", "0.2.0", "strs = { version = \"1.0.0\" }\n"),
+		"util/util.clav":                "var x = 1;\n",
+		"registry/strs/1.0.0/clav.toml": manifest("strs", "1.0.0", ""),
+	})
+	app := filepath.Join(root, "app")
+	registry := filepath.Join(root, "registry")
+
+	lock, err := pkg.Resolve(app, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Write(app); err != nil {
+		t.Fatal(err)
+	}
+	read, err := pkg.ReadLockfile(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Packages) != 2 || read.Packages[0].Name != "strs" || read.Packages[1].Source != "path+../util" {
+		t.Fatalf("unexpected lockfile %+v", read)
+	}
+	if err := pkg.Vendor(app, registry, read); err != nil {
+		t.Fatal(err)
+	}
+	if err := pkg.Verify(app, read); err != nil {
+		t.Fatal(err)
+	}
+	packages, err := pkg.Packages(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(packages["util"], "util.clav")); err != nil {
+		t.Errorf("util was not vendored: %v", err)
+	}
+
+	writeFiles(t, app, map[string]string{"clav_modules/util/util.clav": "var x = 2;\n"})
+	if err := pkg.Verify(app, read); err == nil {
+		t.Error("expected verify to notice the modified package")
+	}
+}
+
+func TestConflictingVersions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/clav.toml":                 manifest("app", "0.1.0", "util = { path = \"../util\" }\nstrs = \"1.0.0\"\n"),
+		"util/clav.toml":                manifest("util", "0.2.0", "strs = \"2.0.0\"\n"),
+		"registry/strs/1.0.0/clav.toml": manifest("strs", "1.0.0", ""),
+		"registry/strs/2.0.0/clav.toml": manifest("strs", "2.0.0", ""),
+	})
+	_, err := pkg.Resolve(filepath.Join(root, "app"), filepath.Join(root, "registry"))
+	if err == nil || !strings.Contains(err.Error(), "conflicting") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"package name":       {"clav.toml": manifest("../app", "0.1.0", "")},
+		"version":            {"clav.toml": manifest("app", "../0.1.0", "")},
+		"dependency":         {"clav.toml": manifest("app", "0.1.0", "\"../x\" = \"1.0.0\"\n")},
+		"dependency dir":     {"clav.toml": manifest("app", "0.1.0", "\"a/b\" = { path = \"../b\" }\n")},
+		"dependency version": {"clav.toml": manifest("app", "0.1.0", "x = \"../../1.0.0\"\n")},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		if _, err := pkg.ReadManifest(dir); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("%s: expected the manifest to be rejected, got %v", name, err)
+		}
+	}
+
+	for name, locked := range map[string]string{
+		"name":    `name = "../../x"` + "\nsource = \"registry+1.0.0\"\n",
+		"version": `name = "x"` + "\nversion = \"..\"\nsource = \"path+../x\"\n",
+		"source":  `name = "x"` + "\nsource = \"registry+../../1.0.0\"\n",
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"clav.lock": "[[package]]\n" + locked})
+		if _, err := pkg.ReadLockfile(dir); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("lockfile %s: expected it to be rejected, got %v", name, err)
+		}
+	}
+
+	// Lockfiles built in code are checked too.
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{"clav.toml": manifest("app", "0.1.0", "")})
+	lock := pkg.Lockfile{Packages: []pkg.Locked{{Name: "..", Source: "path+."}}}
+	if err := pkg.Vendor(project, "", lock); err == nil {
+		t.Error("expected Vendor to reject the package name")
+	}
+}
+
+func TestVendorKeepsModulesOnError(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/clav.toml":  manifest("app", "0.1.0", "util = { path = \"../util\" }\n"),
+		"util/clav.toml": manifest("util", "0.2.0", ""),
+		"util/util.clav": "var x = 1;\n",
+	})
+	app := filepath.Join(root, "app")
+	lock, err := pkg.Resolve(app, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pkg.Vendor(app, "", lock); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, root, map[string]string{"util/util.clav": "var x = 2;\n"})
+	if err := pkg.Vendor(app, "", lock); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Fatalf("expected a hash mismatch, got %v", err)
+	}
+	if err := pkg.Verify(app, lock); err != nil {
+		t.Errorf("the vendored packages were touched: %v", err)
+	}
+	entries, err := os.ReadDir(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("the project holds %v, want the manifest and %s", entries, pkg.VendorDir)
+	}
+}
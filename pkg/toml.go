@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// table is a decoded TOML table. Values are strings, nested tables or, for
+// [[array]] headers, slices of tables.
+type table map[string]any
+
+// parseTOML decodes the subset of TOML used by manifests and lockfiles:
+// [table] and [[array]] headers, comments and key = value pairs where the
+// value is a basic string or an inline table of strings.
+func parseTOML(source string) (table, error) {
+	root := table{}
+	current := root
+	lines := bufio.NewScanner(strings.NewReader(source))
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(lines.Text()))
+		var err error
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "[["):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]"))
+			current = table{}
+			list, _ := root[name].([]table)
+			root[name] = append(list, current)
+		case strings.HasPrefix(line, "["):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			current = table{}
+			root[name] = current
+		default:
+			err = parsePair(line, current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return root, lines.Err()
+}
+
+func parsePair(line string, into table) error {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return fmt.Errorf("expected key = value, got %q", line)
+	}
+	key = unquoteKey(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		if !strings.HasSuffix(value, "}") {
+			return fmt.Errorf("unterminated inline table for %q", key)
+		}
+		inline := table{}
+		for _, pair := range splitInline(value[1 : len(value)-1]) {
+			if err := parsePair(pair, inline); err != nil {
+				return err
+			}
+		}
+		into[key] = inline
+		return nil
+	}
+	s, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return fmt.Errorf("value of %q must be a string", key)
+	}
+	into[key] = s
+	return nil
+}
+
+// splitInline splits the body of an inline table on commas outside strings.
+func splitInline(body string) []string {
+	var pairs []string
+	start, quoted := 0, false
+	for j := 0; j < len(body); j++ {
+		switch body[j] {
+		case '\\':
+			j++
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				pairs = append(pairs, body[start:j])
+				start = j + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(body[start:]); rest != "" {
+		pairs = append(pairs, rest)
+	}
+	return pairs
+}
+
+func stripComment(line string) string {
+	quoted := false
+	for j := 0; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case '"':
+			quoted = !quoted
+		case '#':
+			if !quoted {
+				return line[:j]
+			}
+		}
+	}
+	return line
+}
+
+func unquoteKey(key string) string {
+	if s, err := strconv.Unquote(key); err == nil {
+		return s
+	}
+	return key
+}
+
+func (t table) string(key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// quote encodes s as a TOML basic string.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
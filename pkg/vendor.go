@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Vendor copies every package in lock into the project's vendor directory,
+// checking each against its recorded hash. The vendor directory is only
+// replaced once every package has been copied and checked.
+func Vendor(project, registry string, lock Lockfile) error {
+	if registry == "" {
+		m, err := ReadManifest(project)
+		if err != nil {
+			return err
+		}
+		registry = m.Registry
+	}
+	sources := make([]string, len(lock.Packages))
+	for j, p := range lock.Packages {
+		if err := p.check(); err != nil {
+			return err
+		}
+		src, err := sourceDir(project, registry, p)
+		if err != nil {
+			return err
+		}
+		if err := checkHash(src, p); err != nil {
+			return err
+		}
+		sources[j] = src
+	}
+
+	staging, err := os.MkdirTemp(project, VendorDir+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	for j, p := range lock.Packages {
+		dst := filepath.Join(staging, p.Name)
+		if err := copyDir(sources[j], dst); err != nil {
+			return err
+		}
+		// The source may have changed while it was copied.
+		if err := checkHash(dst, p); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(staging, dirPerm); err != nil {
+		return err
+	}
+	vendor := filepath.Join(project, VendorDir)
+	if err := os.RemoveAll(vendor); err != nil {
+		return err
+	}
+	return os.Rename(staging, vendor)
+}
+
+func checkHash(dir string, p Locked) error {
+	hash, err := HashDir(dir)
+	if err != nil {
+		return err
+	}
+	if hash != p.Hash {
+		return fmt.Errorf("package %s changed since it was locked: run `clav pkg lock`", p.Name)
+	}
+	return nil
+}
+
+// Verify checks that the vendored packages match the lockfile.
+func Verify(project string, lock Lockfile) error {
+	for _, p := range lock.Packages {
+		if err := p.check(); err != nil {
+			return err
+		}
+		hash, err := HashDir(filepath.Join(project, VendorDir, p.Name))
+		if err != nil {
+			return fmt.Errorf("package %s: %w", p.Name, err)
+		}
+		if hash != p.Hash {
+			return fmt.Errorf("package %s does not match its locked hash", p.Name)
+		}
+	}
+	return nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir() && d.Name() == VendorDir:
+			return filepath.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(target, dirPerm)
+		case !d.Type().IsRegular() || d.Name() == LockFile:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, filePerm)
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/pkg"
+)
+
+const pkgUsage = `usage: clav pkg [--registry dir] <command> [project]
+
+commands:
+  lock    resolve dependencies and write clav.lock
+  vendor  copy locked dependencies into clav_modules, locking first if needed
+  verify  check vendored dependencies against clav.lock
+`
+
+func pkgCommand(args []string) {
+	flags := flag.NewFlagSet("pkg", flag.ExitOnError)
+	registry := flags.String("registry", "", "use the file based registry in `dir`")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), pkgUsage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	project := "."
+	if flags.NArg() == 2 {
+		project = flags.Arg(1)
+	}
+
+	var err error
+	switch flags.Arg(0) {
+	case "lock":
+		err = pkgLock(project, *registry)
+	case "vendor":
+		err = pkgVendor(project, *registry)
+	case "verify":
+		var lock pkg.Lockfile
+		if lock, err = pkg.ReadLockfile(project); err == nil {
+			err = pkg.Verify(project, lock)
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func pkgLock(project, registry string) error {
+	lock, err := pkg.Resolve(project, registry)
+	if err != nil {
+		return err
+	}
+	if err := lock.Write(project); err != nil {
+		return err
+	}
+	log.Printf("Locked %d packages", len(lock.Packages))
+	return nil
+}
+
+func pkgVendor(project, registry string) error {
+	lock, err := pkg.ReadLockfile(project)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := pkgLock(project, registry); err != nil {
+			return err
+		}
+		lock, err = pkg.ReadLockfile(project)
+	}
+	if err != nil {
+		return err
+	}
+	if err := pkg.Vendor(project, registry, lock); err != nil {
+		return err
+	}
+	log.Printf("Vendored %d packages into %s", len(lock.Packages), pkg.VendorDir)
+	return nil
+}
+
+// packageOptions lets imports in the script at path find the packages
+// locked by its enclosing project, if it has one.
+func packageOptions(path string) []interpreter.Option {
+	project, ok := pkg.FindProject(filepath.Dir(path))
+	if !ok {
+		return nil
+	}
+	packages, err := pkg.Packages(project)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return []interpreter.Option{interpreter.WithPackages(packages)}
+}
@@ -0,0 +1,220 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+// Field numbers of the messages in pprof's profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileMapping           = 3
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	mappingID              = 1
+	mappingFilename        = 5
+	mappingHasFunctions    = 7
+	mappingHasFilenames    = 8
+	mappingHasLineNumbers  = 9
+	mappingHasInlineFrames = 10
+
+	locationID        = 1
+	locationMappingID = 2
+	locationLine      = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+// WritePprof writes the profile in the gzipped protocol buffer format read
+// by go tool pprof. Each sample holds the sample count, the time, and the
+// objects and bytes allocated.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	e := &encoder{strings: map[string]int64{"": 0}, stringTable: []string{""}}
+	sampleTypes := [][2]string{
+		{"samples", "count"},
+		{"time", "nanoseconds"},
+		{"alloc_objects", "count"},
+		{"alloc_space", "bytes"},
+	}
+	var body buffer
+	for _, st := range sampleTypes {
+		body.message(profileSampleType, e.valueType(st[0], st[1]))
+	}
+
+	var mapping buffer
+	mapping.uint64(mappingID, 1)
+	mapping.int64(mappingFilename, e.string("clav"))
+	for _, field := range []int{mappingHasFunctions, mappingHasFilenames, mappingHasLineNumbers, mappingHasInlineFrames} {
+		mapping.uint64(field, 1)
+	}
+	body.message(profileMapping, mapping)
+
+	// Every distinct function and line gets an id, in the order seen.
+	type function struct{ name, file string }
+	functions := map[function]uint64{}
+	locations := map[interpreter.Frame]uint64{}
+	var functionMessages, locationMessages []buffer
+	for _, s := range p.order {
+		var sample buffer
+		frames := s.frames()
+		ids := make([]uint64, 0, len(frames))
+		for _, frame := range frames {
+			fn := function{frame.Function, frame.File}
+			fid, ok := functions[fn]
+			if !ok {
+				fid = uint64(len(functions) + 1)
+				functions[fn] = fid
+				// pprof drops anything in angle brackets from names as
+				// template arguments, which would leave <script> nameless.
+				name := strings.Trim(fn.name, "<>")
+				var m buffer
+				m.uint64(functionID, fid)
+				m.int64(functionName, e.string(name))
+				m.int64(functionSystemName, e.string(name))
+				m.int64(functionFilename, e.string(fn.file))
+				functionMessages = append(functionMessages, m)
+			}
+			lid, ok := locations[frame]
+			if !ok {
+				lid = uint64(len(locations) + 1)
+				locations[frame] = lid
+				var line, m buffer
+				line.uint64(lineFunctionID, fid)
+				line.int64(lineLine, int64(frame.Line))
+				m.uint64(locationID, lid)
+				m.uint64(locationMappingID, 1)
+				m.message(locationLine, line)
+				locationMessages = append(locationMessages, m)
+			}
+			ids = append(ids, lid)
+		}
+		sample.packedUint64(sampleLocationID, ids)
+		sample.packedInt64(sampleValue, []int64{s.samples, int64(s.time), s.objects, s.bytes})
+		body.message(profileSample, sample)
+	}
+	for _, m := range locationMessages {
+		body.message(profileLocation, m)
+	}
+	for _, m := range functionMessages {
+		body.message(profileFunction, m)
+	}
+
+	if !p.start.IsZero() {
+		body.int64(profileTimeNanos, p.start.UnixNano())
+		body.int64(profileDurationNanos, int64(p.last.Sub(p.start)))
+	}
+	body.message(profilePeriodType, e.valueType("wall", "nanoseconds"))
+	body.int64(profilePeriod, int64(p.period))
+	body.int64(profileDefaultSampleType, e.string("time"))
+	// The string table goes last, once every string has been interned.
+	for _, s := range e.stringTable {
+		body.bytes(profileStringTable, []byte(s))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(body); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// encoder interns the strings of a profile.
+type encoder struct {
+	strings     map[string]int64
+	stringTable []string
+}
+
+func (e *encoder) string(s string) int64 {
+	if index, ok := e.strings[s]; ok {
+		return index
+	}
+	index := int64(len(e.stringTable))
+	e.strings[s] = index
+	e.stringTable = append(e.stringTable, s)
+	return index
+}
+
+func (e *encoder) valueType(typ, unit string) buffer {
+	var b buffer
+	b.int64(valueTypeType, e.string(typ))
+	b.int64(valueTypeUnit, e.string(unit))
+	return b
+}
+
+// buffer accumulates an encoded protocol buffer message.
+type buffer []byte
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *buffer) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *buffer) key(field, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *buffer) uint64(field int, v uint64) {
+	b.key(field, wireVarint)
+	b.varint(v)
+}
+
+func (b *buffer) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *buffer) bytes(field int, data []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+func (b *buffer) message(field int, m buffer) {
+	b.bytes(field, m)
+}
+
+func (b *buffer) packedUint64(field int, values []uint64) {
+	var packed buffer
+	for _, v := range values {
+		packed.varint(v)
+	}
+	b.bytes(field, packed)
+}
+
+func (b *buffer) packedInt64(field int, values []int64) {
+	var packed buffer
+	for _, v := range values {
+		packed.varint(uint64(v))
+	}
+	b.bytes(field, packed)
+}
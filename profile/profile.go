@@ -0,0 +1,225 @@
+// Package profile attributes the time and allocations of clav programs to
+// the functions and lines that spent them.
+//
+// The Profiler is called by the Interpreter before every statement. The
+// time until the next statement is charged to the stack the statement runs
+// in, and every period of that time also counts as one sample of the
+// stack, so that the profile reads like one taken by a sampling profiler.
+// Allocations are charged to the stack that makes them.
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+// DefaultPeriod is the sampling period used by clav run.
+const DefaultPeriod = time.Millisecond
+
+// Profiler is an interpreter.Profiler recording where a program spends its
+// time and memory. A Profiler is used by a single Interpreter.
+type Profiler struct {
+	period time.Duration
+	now    func() time.Time
+
+	start, last time.Time
+	// sampled is the time up to which samples have been counted.
+	sampled time.Time
+	// current is the stack the last statement ran in.
+	current *stack
+	// here is the stack the program is in, followed as frames are pushed
+	// and popped and statements run.
+	here *stack
+
+	// order lists stacks as they were first charged.
+	order []*stack
+}
+
+// stack holds what was spent by a stack of frames. The stacks form a
+// tree, so that moving to another line or making a call finds the next
+// stack without comparing frames.
+type stack struct {
+	// frame is the innermost frame and caller the stack that called it,
+	// nil for the outermost frame.
+	frame  interpreter.Frame
+	caller *stack
+	// lines holds the stacks that differ only in the line of the innermost
+	// frame, and is shared by all of them.
+	lines map[int]*stack
+	// calls holds the stacks of the calls made from this one, before their
+	// first statement.
+	calls map[callee]*stack
+	used  bool
+
+	samples int64
+	time    time.Duration
+	objects int64
+	bytes   int64
+}
+
+type callee struct{ function, file string }
+
+// New returns a Profiler counting a sample every period and reading the
+// time from now.
+func New(period time.Duration, now func() time.Time) *Profiler {
+	return &Profiler{period: period, now: now}
+}
+
+// Statement implements interpreter.Profiler.
+func (p *Profiler) Statement(i *interpreter.Interpreter, line int) {
+	now := p.now()
+	if p.current == nil {
+		p.start, p.sampled = now, now
+	} else {
+		p.charge(now)
+	}
+	p.last = now
+	p.here = p.top(i).at(line)
+	p.current = p.use(p.here)
+}
+
+// PushFrame implements interpreter.Profiler.
+func (p *Profiler) PushFrame(i *interpreter.Interpreter, function, file string) {
+	if p.here == nil {
+		p.here = build(i.Stack())
+		return
+	}
+	p.here = p.here.call(function, file)
+}
+
+// PopFrame implements interpreter.Profiler. The frame's PushFrame has
+// already placed the Profiler in the stack.
+func (p *Profiler) PopFrame(*interpreter.Interpreter) {
+	p.here = p.here.caller
+}
+
+// Allocate implements interpreter.Profiler.
+func (p *Profiler) Allocate(i *interpreter.Interpreter, bytes int) {
+	s := p.use(p.top(i))
+	s.objects++
+	s.bytes += int64(bytes)
+}
+
+// Stop charges the time since the last statement. Call it once the program
+// has finished.
+func (p *Profiler) Stop() {
+	if p.current != nil {
+		p.charge(p.now())
+		p.current = nil
+	}
+}
+
+// charge attributes the time since the last statement to its stack.
+func (p *Profiler) charge(now time.Time) {
+	p.current.time += now.Sub(p.last)
+	if p.period > 0 {
+		n := now.Sub(p.sampled) / p.period
+		p.current.samples += int64(n)
+		p.sampled = p.sampled.Add(n * p.period)
+	}
+	p.last = now
+}
+
+// top returns the stack the program is in, which is only read from the
+// Interpreter the first time.
+func (p *Profiler) top(i *interpreter.Interpreter) *stack {
+	if p.here == nil {
+		p.here = build(i.Stack())
+	}
+	return p.here
+}
+
+// use records that something was charged to s.
+func (p *Profiler) use(s *stack) *stack {
+	if !s.used {
+		s.used = true
+		p.order = append(p.order, s)
+	}
+	return s
+}
+
+// build returns the stack of frames, innermost first, in a tree of its own.
+func build(frames []interpreter.Frame) *stack {
+	var s *stack
+	for j := len(frames) - 1; j >= 0; j-- {
+		f := frames[j]
+		if s == nil {
+			s = &stack{frame: interpreter.Frame{Function: f.Function, File: f.File}, lines: map[int]*stack{}}
+			s.lines[0] = s
+		} else {
+			s = s.call(f.Function, f.File)
+		}
+		s = s.at(f.Line)
+	}
+	return s
+}
+
+// at returns the stack that differs from s only in being at line.
+func (s *stack) at(line int) *stack {
+	if t, ok := s.lines[line]; ok {
+		return t
+	}
+	t := &stack{frame: s.frame, caller: s.caller, lines: s.lines}
+	t.frame.Line = line
+	s.lines[line] = t
+	return t
+}
+
+// call returns the stack of a call to function, declared in file, made
+// from s.
+func (s *stack) call(function, file string) *stack {
+	key := callee{function, file}
+	if t, ok := s.calls[key]; ok {
+		return t
+	}
+	t := &stack{frame: interpreter.Frame{Function: function, File: file}, caller: s, lines: map[int]*stack{}}
+	t.lines[0] = t
+	if s.calls == nil {
+		s.calls = map[callee]*stack{}
+	}
+	s.calls[key] = t
+	return t
+}
+
+// frames returns the frames of s, innermost first.
+func (s *stack) frames() []interpreter.Frame {
+	var frames []interpreter.Frame
+	for ; s != nil; s = s.caller {
+		frames = append(frames, s.frame)
+	}
+	return frames
+}
+
+// WriteFolded writes the time spent in each stack in the folded format
+// read by flame graph tools: the frames from the outermost in, separated
+// by semicolons, followed by the time in microseconds.
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range p.order {
+		micros := s.time.Microseconds()
+		if micros == 0 {
+			continue
+		}
+		frames := s.frames()
+		names := make([]string, len(frames))
+		for j, f := range frames {
+			names[len(frames)-1-j] = frameName(f)
+		}
+		fmt.Fprintf(bw, "%s %d\n", strings.Join(names, ";"), micros)
+	}
+	return bw.Flush()
+}
+
+func frameName(f interpreter.Frame) string {
+	file := "<script>"
+	if f.File != "" {
+		file = filepath.Base(f.File)
+	}
+	return fmt.Sprintf("%s (%s:%d)", f.Function, file, f.Line)
+}
@@ -0,0 +1,214 @@
+package profile_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/profile"
+	"github.com/it-a-me/clavlang/scanner"
+)
+
+const program = `fun square(n) {
+  return n * n;
+}
+fun sum(a, b) {
+  var total = 0;
+  total = total + square(a);
+  return total + square(b);
+}
+print sum(3, 4);
+`
+
+// run profiles source with a clock that advances a millisecond every time
+// it is read.
+func run(t *testing.T, source string) *profile.Profiler {
+	t.Helper()
+	s := scanner.NewScanner(source)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	clock := time.Unix(0, 0)
+	now := func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+	profiler := profile.New(2*time.Millisecond, now)
+	i := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard), interpreter.WithProfiler(profiler))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+	profiler.Stop()
+	return profiler
+}
+
+func TestFolded(t *testing.T) {
+	var out strings.Builder
+	if err := run(t, program).WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+	// Every statement takes the millisecond until the next one is reached.
+	want := `<script> (<script>:1) 1000
+<script> (<script>:4) 1000
+<script> (<script>:9) 1000
+<script> (<script>:9);sum (<script>:5) 1000
+<script> (<script>:9);sum (<script>:6) 1000
+<script> (<script>:9);sum (<script>:6);square (<script>:2) 1000
+<script> (<script>:9);sum (<script>:7) 1000
+<script> (<script>:9);sum (<script>:7);square (<script>:2) 1000
+`
+	if out.String() != want {
+		t.Errorf("folded stacks:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRecursion(t *testing.T) {
+	const source = `fun down(items, n) {
+  try { items.get(n); } catch (e) { return 0; }
+  return down(items, n + 1);
+}
+down([1], 0);
+down([1], 0);
+`
+	var out strings.Builder
+	if err := run(t, source).WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+	// The second call runs through the same stacks as the first.
+	want := `<script> (<script>:1) 1000
+<script> (<script>:5) 1000
+<script> (<script>:5);down (<script>:2) 2000
+<script> (<script>:5);down (<script>:3) 1000
+<script> (<script>:5);down (<script>:3);down (<script>:2) 3000
+<script> (<script>:6) 1000
+<script> (<script>:6);down (<script>:2) 2000
+<script> (<script>:6);down (<script>:3) 1000
+<script> (<script>:6);down (<script>:3);down (<script>:2) 3000
+`
+	if out.String() != want {
+		t.Errorf("folded stacks:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPprof(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(t, program).WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := decode(t, data)
+	var table []string
+	for _, s := range fields[6] {
+		table = append(table, string(s.bytes))
+	}
+	if len(table) == 0 || table[0] != "" {
+		t.Fatalf("string table %q must start with the empty string", table)
+	}
+	for _, want := range []string{"samples", "time", "nanoseconds", "alloc_space", "bytes", "script", "sum", "square"} {
+		if !slices.Contains(table, want) {
+			t.Errorf("string table %q lacks %q", table, want)
+		}
+	}
+	if len(fields[1]) != 4 {
+		t.Errorf("%d sample types, want 4", len(fields[1]))
+	}
+	if got := len(fields[5]); got != 3 {
+		t.Errorf("%d functions, want 3", got)
+	}
+	if got := len(fields[4]); got != 7 {
+		t.Errorf("%d locations, want 7", got)
+	}
+	if fields[12][0].varint != uint64(2*time.Millisecond) {
+		t.Errorf("period %d", fields[12][0].varint)
+	}
+
+	// Add up the values of all samples: samples, time, objects, bytes.
+	var totals [4]uint64
+	for _, sample := range fields[2] {
+		values := packed(t, decode(t, sample.bytes)[2][0].bytes)
+		if len(values) != 4 {
+			t.Fatalf("sample values %v", values)
+		}
+		for j, v := range values {
+			totals[j] += v
+		}
+	}
+	if totals[0] != 4 || totals[1] != uint64(8*time.Millisecond) {
+		t.Errorf("%d samples over %v, want 4 over 8ms", totals[0], time.Duration(totals[1]))
+	}
+	if totals[2] == 0 || totals[3] == 0 {
+		t.Errorf("no allocations recorded: %v", totals)
+	}
+}
+
+type field struct {
+	varint uint64
+	bytes  []byte
+}
+
+// decode splits a protocol buffer message into its fields by number.
+func decode(t *testing.T, data []byte) map[int][]field {
+	t.Helper()
+	fields := map[int][]field{}
+	for len(data) > 0 {
+		key, n := uvarint(t, data)
+		data = data[n:]
+		switch key & 7 {
+		case 0:
+			v, n := uvarint(t, data)
+			data = data[n:]
+			fields[int(key>>3)] = append(fields[int(key>>3)], field{varint: v})
+		case 2:
+			length, n := uvarint(t, data)
+			data = data[n:]
+			fields[int(key>>3)] = append(fields[int(key>>3)], field{bytes: data[:length]})
+			data = data[length:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return fields
+}
+
+func packed(t *testing.T, data []byte) []uint64 {
+	t.Helper()
+	var values []uint64
+	for len(data) > 0 {
+		v, n := uvarint(t, data)
+		values = append(values, v)
+		data = data[n:]
+	}
+	return values
+}
+
+func uvarint(t *testing.T, data []byte) (uint64, int) {
+	t.Helper()
+	var v uint64
+	for i, b := range data {
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, i + 1
+		}
+	}
+	t.Fatal("truncated varint")
+	return 0, 0
+}
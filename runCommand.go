@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/profile"
+)
+
+func runCommand(args []string) {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: clav run [flags] file.clav [args...]")
+		flags.PrintDefaults()
+	}
+	interpreterOptions := interpreterFlags(flags)
+	traced := traceFlags(flags)
+	pprof := flags.String("profile", "", "write a time and allocation profile for go tool pprof to `file`")
+	folded := flags.String("profile-folded", "", "write the time spent in each stack as folded stacks for flame graphs to `file`")
+	_ = flags.Parse(args)
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	opts := append(interpreterOptions(), interpreter.WithArgs(flags.Args()[1:]))
+	var profiler *profile.Profiler
+	if *pprof != "" || *folded != "" {
+		profiler = profile.New(profile.DefaultPeriod, time.Now)
+		opts = append(opts, interpreter.WithProfiler(profiler))
+	}
+	err := runFile(flags.Arg(0), *traced, opts)
+	if profiler != nil {
+		// The profile of a failed run is still worth looking at.
+		profiler.Stop()
+		if *pprof != "" {
+			writeReport(*pprof, profiler.WritePprof)
+		}
+		if *folded != "" {
+			writeReport(*folded, profiler.WriteFolded)
+		}
+	}
+	exitOnError(err)
+}
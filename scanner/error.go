@@ -10,3 +10,12 @@ type ScanError struct {
 func (e ScanError) Error() string {
 	return fmt.Sprintf("Error on line %d: %s", e.line, e.err)
 }
+
+func (e ScanError) Line() int {
+	return e.line
+}
+
+// Message is the error without its line number.
+func (e ScanError) Message() string {
+	return e.err
+}
@@ -0,0 +1,29 @@
+package scanner_test
+
+import (
+	"testing"
+
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+)
+
+func FuzzScan(f *testing.F) {
+	f.Add(`var x = 1.5; print x + 2;`)
+	f.Add(`"unterminated`)
+	f.Add("// comment\n[1, 2].get(0);")
+	f.Add(`123456789012345678901234567890`)
+	f.Fuzz(func(t *testing.T, source string) {
+		s := scanner.NewScanner(source)
+		tokens, _ := s.Scan()
+		if len(tokens) == 0 || tokens[len(tokens)-1].Type != token.EOF {
+			t.Fatalf("token stream for %q does not end with EOF", source)
+		}
+		line := 1
+		for _, tok := range tokens {
+			if tok.Line < line {
+				t.Fatalf("token %v on line %d follows line %d", tok.Type, tok.Line, line)
+			}
+			line = tok.Line
+		}
+	})
+}
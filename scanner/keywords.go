@@ -1,16 +1,35 @@
 package scanner
 
-import "github.com/it-a-me/clavlang/token"
+import (
+	"maps"
+	"slices"
+
+	"github.com/it-a-me/clavlang/token"
+)
 
 func Keywords(identifier string) (token.Type, bool) {
-	keywords := map[string]token.Type{
+	kw, ok := keywords()[identifier]
+	return kw, ok
+}
+
+// KeywordNames lists every reserved word in alphabetical order.
+func KeywordNames() []string {
+	return slices.Sorted(maps.Keys(keywords()))
+}
+
+func keywords() map[string]token.Type {
+	return map[string]token.Type{
 		"and":    token.And,
+		"as":     token.As,
+		"catch":  token.Catch,
 		"class":  token.Class,
 		"else":   token.Else,
 		"false":  token.False,
 		"for":    token.For,
+		"from":   token.From,
 		"fun":    token.Fun,
 		"if":     token.If,
+		"import": token.Import,
 		"nil":    token.Nil,
 		"or":     token.Or,
 		"print":  token.Print,
@@ -18,9 +37,8 @@ func Keywords(identifier string) (token.Type, bool) {
 		"super":  token.Super,
 		"this":   token.This,
 		"true":   token.True,
+		"try":    token.Try,
 		"var":    token.Var,
 		"while":  token.While,
 	}
-	kw, ok := keywords[identifier]
-	return kw, ok
 }
@@ -3,6 +3,7 @@ package scanner
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/it-a-me/clavlang/token"
 	"github.com/it-a-me/clavlang/types"
@@ -16,13 +17,22 @@ type Scanner struct {
 	current int
 	line    int
 
+	// trivia collects comments and blank lines until the next token.
+	// lineEmpty reports whether the current line holds nothing but
+	// whitespace so far and newline whether a line ended since the last
+	// token.
+	trivia    []token.Trivia
+	lineEmpty bool
+	newline   bool
+
 	errors []error
 }
 
 func NewScanner(source string) Scanner {
 	return Scanner{
-		source: source,
-		line:   1,
+		source:    source,
+		line:      1,
+		lineEmpty: true,
 	}
 }
 
@@ -33,7 +43,10 @@ func (s *Scanner) Scan() ([]token.Token, []error) {
 			s.errors = append(s.errors, err)
 		}
 	}
-	s.tokens = append(s.tokens, token.NewToken(token.EOF, "", nil, s.line))
+	eof := token.NewToken(token.EOF, "", nil, s.line)
+	eof.Offset = len(s.source)
+	eof.Leading = s.trivia
+	s.tokens = append(s.tokens, eof)
 	return s.tokens, s.errors
 }
 
@@ -41,8 +54,7 @@ func (s *Scanner) scanToken() error {
 	c := s.advance()
 	switch {
 	case isDigit(c):
-		s.handleNumber()
-		return nil
+		return s.handleNumber()
 	case isAlpha(c):
 		s.handleIdentifier()
 		return nil
@@ -56,6 +68,10 @@ func (s *Scanner) scanToken() error {
 		s.addToken(token.LeftBrace, nil)
 	case '}':
 		s.addToken(token.RightBrace, nil)
+	case '[':
+		s.addToken(token.LeftBracket, nil)
+	case ']':
+		s.addToken(token.RightBracket, nil)
 	case ',':
 		s.addToken(token.Comma, nil)
 	case '.':
@@ -103,6 +119,11 @@ func (s *Scanner) scanToken() error {
 	case '\t':
 	case '\n':
 		s.line++
+		if s.lineEmpty {
+			s.addBlankLine()
+		}
+		s.lineEmpty = true
+		s.newline = true
 	case '"':
 		return s.handleString()
 	default:
@@ -113,7 +134,21 @@ func (s *Scanner) scanToken() error {
 
 func (s *Scanner) addToken(tokenType token.Type, literal types.ClavType) {
 	lexeme := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, token.NewToken(tokenType, lexeme, literal, s.line))
+	t := token.NewToken(tokenType, lexeme, literal, s.line)
+	t.Offset = s.start
+	t.Leading = s.trivia
+	s.tokens = append(s.tokens, t)
+	s.trivia = nil
+	s.lineEmpty = false
+	s.newline = false
+}
+
+// addBlankLine records an empty line, merging it with the previous one.
+func (s *Scanner) addBlankLine() {
+	if n := len(s.trivia); n > 0 && s.trivia[n-1].Kind == token.BlankLine {
+		return
+	}
+	s.trivia = append(s.trivia, token.Trivia{Kind: token.BlankLine})
 }
 
 func (s *Scanner) advance() byte {
@@ -169,11 +204,13 @@ func (s *Scanner) handleString() error {
 	return nil
 }
 
-func (s *Scanner) handleNumber() {
+func (s *Scanner) handleNumber() error {
 	for isDigit(s.peek()) {
 		s.advance()
 	}
 	if s.peek() == '.' && isDigit(s.peekNext()) {
+		// Consume the "."
+		s.advance()
 		for isDigit(s.peek()) {
 			s.advance()
 		}
@@ -181,9 +218,11 @@ func (s *Scanner) handleNumber() {
 	content := s.source[s.start:s.current]
 	f, err := strconv.ParseFloat(content, 64)
 	if err != nil {
-		panic("Error parsing float: " + err.Error())
+		// Only literals too large for a float64 get here.
+		return s.NewError("Number literal out of range")
 	}
 	s.addToken(token.Number, types.Number{Value: f})
+	return nil
 }
 
 func (s *Scanner) handleComment() {
@@ -191,6 +230,14 @@ func (s *Scanner) handleComment() {
 	for s.peek() != '\n' && !s.isAtEnd() {
 		s.advance()
 	}
+	comment := token.Trivia{Kind: token.Comment, Text: strings.TrimRight(s.source[s.start:s.current], " \t\r")}
+	if len(s.tokens) > 0 && !s.newline {
+		last := &s.tokens[len(s.tokens)-1]
+		last.Trailing = append(last.Trailing, comment)
+	} else {
+		s.trivia = append(s.trivia, comment)
+	}
+	s.lineEmpty = false
 }
 
 func (s *Scanner) handleIdentifier() {
@@ -214,7 +261,7 @@ func (s *Scanner) NewError(err string) error {
 }
 
 func isAlpha(char byte) bool {
-	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
 }
 
 func isDigit(char byte) bool {
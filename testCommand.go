@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/it-a-me/clavlang/clavtest"
+	"github.com/it-a-me/clavlang/coverage"
+	"github.com/it-a-me/clavlang/interpreter"
+)
+
+func testCommand(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: clav test [flags] [paths...]")
+		flags.PrintDefaults()
+	}
+	interpreterOptions := interpreterFlags(flags)
+	filter := flags.String("run", "", "run only tests whose name matches `regexp`")
+	junit := flags.String("junit", "", "write a JUnit XML report to `file`")
+	verbose := flags.Bool("v", false, "report passing tests and their output too")
+	cover := flags.Bool("cover", false, "print a line and branch coverage summary")
+	lcov := flags.String("lcov", "", "write coverage in LCOV format to `file`")
+	coverHTML := flags.String("cover-html", "", "write an HTML coverage report to `file`")
+	_ = flags.Parse(args)
+
+	opts := clavtest.Options{Interpreter: interpreterOptions(), File: packageOptions}
+	var profile *coverage.Profile
+	if *cover || *lcov != "" || *coverHTML != "" {
+		profile = coverage.New()
+		opts.Interpreter = append(opts.Interpreter, interpreter.WithCoverage(profile))
+	}
+	if *filter != "" {
+		re, err := regexp.Compile(*filter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Filter = re
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := clavtest.Discover(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var results []clavtest.Result
+	// A file that does not load has no results, so it is counted apart
+	// from the tests that failed.
+	failed, broken := 0, 0
+	for _, file := range files {
+		fileResults, err := clavtest.RunFile(context.Background(), file, opts)
+		if err != nil {
+			log.Printf("%s: %v", file, err)
+			broken++
+			continue
+		}
+		for _, r := range fileResults {
+			report(r, *verbose)
+			if !r.Passed() {
+				failed++
+			}
+		}
+		results = append(results, fileResults...)
+	}
+
+	if *junit != "" {
+		writeReport(*junit, func(w io.Writer) error { return clavtest.WriteJUnit(w, results) })
+	}
+	if profile != nil {
+		// Like Go, only report the code under test.
+		profile.Remove(func(path string) bool { return strings.HasSuffix(path, clavtest.FileSuffix) })
+		if *cover {
+			if err := profile.WriteText(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *lcov != "" {
+			writeReport(*lcov, profile.WriteLCOV)
+		}
+		if *coverHTML != "" {
+			writeReport(*coverHTML, profile.WriteHTML)
+		}
+	}
+	if failed > 0 || broken > 0 {
+		fmt.Printf("FAIL\t%d passed, %d failed", len(results)-failed, failed)
+		if broken > 0 {
+			fmt.Printf(", %d files did not load", broken)
+		}
+		fmt.Println()
+		os.Exit(1)
+	}
+	fmt.Printf("ok\t%d passed\n", len(results))
+}
+
+// writeReport creates path and writes a report to it with write.
+func writeReport(path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(f); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func report(r clavtest.Result, verbose bool) {
+	if r.Passed() && !verbose {
+		return
+	}
+	status := "PASS"
+	if !r.Passed() {
+		status = "FAIL"
+	}
+	fmt.Printf("--- %s: %s (%s) (%.2fs)\n", status, r.Name, r.File, r.Duration.Seconds())
+	if r.Source != "" {
+		fmt.Printf("    %s:%d: %s\n", r.SourceFile, r.Line, r.Source)
+	}
+	if !r.Passed() {
+		fmt.Printf("    %s\n", r.Err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(r.Output, "\n"), "\n") {
+		if line != "" {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
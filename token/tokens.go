@@ -16,6 +16,8 @@ const (
 	RightParen
 	LeftBrace
 	RightBrace
+	LeftBracket
+	RightBracket
 
 	Comma
 	Dot
@@ -61,6 +63,11 @@ const (
 	True
 	Var
 	While
+	Try
+	Catch
+	Import
+	From
+	As
 
 	EOF
 )
@@ -70,17 +77,41 @@ type Token struct {
 	Lexeme  string
 	Literal types.ClavType
 	Line    int
+	// Offset is the byte offset of the token's first character in the
+	// source.
+	Offset int
+
+	// Leading holds the comments and blank lines between the previous token
+	// and this one, Trailing a comment later on the line this token ends.
+	Leading  []Trivia
+	Trailing []Trivia
 }
 
 func NewToken(tokenType Type, lexeme string, literal types.ClavType, line int) Token {
 	return Token{
-		tokenType,
-		lexeme,
-		literal,
-		line,
+		Type:    tokenType,
+		Lexeme:  lexeme,
+		Literal: literal,
+		Line:    line,
 	}
 }
 
+// Trivia is source text the parser ignores but a formatter has to keep.
+type Trivia struct {
+	Kind TriviaKind
+	// Text is the comment including its leading "//" and is empty for
+	// blank lines.
+	Text string
+}
+
+type TriviaKind int
+
+const (
+	Comment TriviaKind = iota
+	// BlankLine stands for one or more consecutive empty lines.
+	BlankLine
+)
+
 func (t *Token) String() string {
 	return fmt.Sprintf("%s %s %v", t.Type.String(), t.Lexeme, t.Literal)
 }
@@ -12,46 +12,53 @@ func _() {
 	_ = x[RightParen-1]
 	_ = x[LeftBrace-2]
 	_ = x[RightBrace-3]
-	_ = x[Comma-4]
-	_ = x[Dot-5]
-	_ = x[Minus-6]
-	_ = x[Plus-7]
-	_ = x[Semicolon-8]
-	_ = x[Slash-9]
-	_ = x[Star-10]
-	_ = x[Bang-11]
-	_ = x[BangEqual-12]
-	_ = x[Equal-13]
-	_ = x[EqualEqual-14]
-	_ = x[Greater-15]
-	_ = x[GreaterEqual-16]
-	_ = x[Less-17]
-	_ = x[LessEqual-18]
-	_ = x[Identifier-19]
-	_ = x[String-20]
-	_ = x[Number-21]
-	_ = x[And-22]
-	_ = x[Class-23]
-	_ = x[Else-24]
-	_ = x[False-25]
-	_ = x[Fun-26]
-	_ = x[For-27]
-	_ = x[If-28]
-	_ = x[Nil-29]
-	_ = x[Or-30]
-	_ = x[Print-31]
-	_ = x[Return-32]
-	_ = x[Super-33]
-	_ = x[This-34]
-	_ = x[True-35]
-	_ = x[Var-36]
-	_ = x[While-37]
-	_ = x[EOF-38]
+	_ = x[LeftBracket-4]
+	_ = x[RightBracket-5]
+	_ = x[Comma-6]
+	_ = x[Dot-7]
+	_ = x[Minus-8]
+	_ = x[Plus-9]
+	_ = x[Semicolon-10]
+	_ = x[Slash-11]
+	_ = x[Star-12]
+	_ = x[Bang-13]
+	_ = x[BangEqual-14]
+	_ = x[Equal-15]
+	_ = x[EqualEqual-16]
+	_ = x[Greater-17]
+	_ = x[GreaterEqual-18]
+	_ = x[Less-19]
+	_ = x[LessEqual-20]
+	_ = x[Identifier-21]
+	_ = x[String-22]
+	_ = x[Number-23]
+	_ = x[And-24]
+	_ = x[Class-25]
+	_ = x[Else-26]
+	_ = x[False-27]
+	_ = x[Fun-28]
+	_ = x[For-29]
+	_ = x[If-30]
+	_ = x[Nil-31]
+	_ = x[Or-32]
+	_ = x[Print-33]
+	_ = x[Return-34]
+	_ = x[Super-35]
+	_ = x[This-36]
+	_ = x[True-37]
+	_ = x[Var-38]
+	_ = x[While-39]
+	_ = x[Try-40]
+	_ = x[Catch-41]
+	_ = x[Import-42]
+	_ = x[From-43]
+	_ = x[As-44]
+	_ = x[EOF-45]
 }
 
-const _Type_name = "LeftParenRightParenLeftBraceRightBraceCommaDotMinusPlusSemicolonSlashStarBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndClassElseFalseFunForIfNilOrPrintReturnSuperThisTrueVarWhileEOF"
+const _Type_name = "LeftParenRightParenLeftBraceRightBraceLeftBracketRightBracketCommaDotMinusPlusSemicolonSlashStarBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndClassElseFalseFunForIfNilOrPrintReturnSuperThisTrueVarWhileTryCatchImportFromAsEOF"
 
-var _Type_index = [...]uint8{0, 9, 19, 28, 38, 43, 46, 51, 55, 64, 69, 73, 77, 86, 91, 101, 108, 120, 124, 133, 143, 149, 155, 158, 163, 167, 172, 175, 178, 180, 183, 185, 190, 196, 201, 205, 209, 212, 217, 220}
+var _Type_index = [...]uint16{0, 9, 19, 28, 38, 49, 61, 66, 69, 74, 78, 87, 92, 96, 100, 109, 114, 124, 131, 143, 147, 156, 166, 172, 178, 181, 186, 190, 195, 198, 201, 203, 206, 208, 213, 219, 224, 228, 232, 235, 240, 243, 248, 254, 258, 260, 263}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
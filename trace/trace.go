@@ -0,0 +1,119 @@
+// Package trace reports what a clav program does as it runs: the tokens
+// and syntax tree of the script and then every statement, the value of
+// every expression and every scope entered and left. Reports are indented
+// text for people or JSON lines for tools.
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/it-a-me/clavlang/debug"
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/types"
+)
+
+// Tracer is an interpreter.Tracer writing each step to an io.Writer as it
+// happens. A Tracer is used by a single Interpreter.
+type Tracer struct {
+	w io.Writer
+	// json is nil when writing text.
+	json  *json.Encoder
+	depth int
+}
+
+// Event is one line of a trace in JSON form. Kind is one of "token",
+// "ast", "statement", "expression", "push" and "pop". Depth counts the
+// scopes entered by the program, so that an event nests inside the
+// nearest preceding push of a lower depth.
+type Event struct {
+	Kind  string `json:"event"`
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Depth int    `json:"depth"`
+	// Token is the type of a token and Lexeme its text.
+	Token  string `json:"token,omitempty"`
+	Lexeme string `json:"lexeme,omitempty"`
+	// Node is a statement or expression, as rendered by parser.LispStmt
+	// and parser.LispExpr.
+	Node  string `json:"node,omitempty"`
+	Value string `json:"value,omitempty"`
+	Scope string `json:"scope,omitempty"`
+}
+
+// New returns a Tracer writing to w, as JSON lines when asJSON is set.
+func New(w io.Writer, asJSON bool) *Tracer {
+	t := &Tracer{w: w}
+	if asJSON {
+		t.json = json.NewEncoder(w)
+		t.json.SetEscapeHTML(false)
+	}
+	return t
+}
+
+// Tokens reports the tokens of a script.
+func (t *Tracer) Tokens(tokens []token.Token) {
+	for _, tok := range tokens {
+		t.emit(Event{Kind: "token", Line: tok.Line, Token: tok.Type.String(), Lexeme: tok.Lexeme},
+			strings.TrimSuffix(fmt.Sprintf("%d: %s %s", tok.Line, tok.Type, tok.Lexeme), " "))
+	}
+}
+
+// AST reports the statements of a script.
+func (t *Tracer) AST(stmts []parser.Stmt) {
+	for _, stmt := range stmts {
+		line := parser.StmtToken(stmt).Line
+		lisp := parser.LispStmt(stmt)
+		t.emit(Event{Kind: "ast", Line: line, Node: lisp}, fmt.Sprintf("%d: %s", line, lisp))
+	}
+}
+
+// Statement implements interpreter.Tracer.
+func (t *Tracer) Statement(i *interpreter.Interpreter, stmt parser.Stmt) {
+	line := parser.StmtToken(stmt).Line
+	lisp := parser.LispStmt(stmt)
+	t.emit(Event{Kind: "statement", File: file(i), Line: line, Depth: t.depth, Node: lisp},
+		fmt.Sprintf("line %d: %s", line, lisp))
+}
+
+// Expression implements interpreter.Tracer. Literals are left out as their
+// values are plain from the statement.
+func (t *Tracer) Expression(i *interpreter.Interpreter, expr parser.Expr, value types.ClavType) {
+	if _, ok := expr.(parser.Literal); ok {
+		return
+	}
+	lisp, formatted := parser.LispExpr(expr), debug.Format(value)
+	t.emit(Event{Kind: "expression", File: file(i), Line: parser.ExprToken(expr).Line, Depth: t.depth, Node: lisp, Value: formatted},
+		"  "+lisp+" => "+formatted)
+}
+
+// PushScope implements interpreter.Tracer.
+func (t *Tracer) PushScope(i *interpreter.Interpreter, scope string) {
+	t.emit(Event{Kind: "push", File: file(i), Depth: t.depth, Scope: scope}, "{ "+scope)
+	t.depth++
+}
+
+// PopScope implements interpreter.Tracer.
+func (t *Tracer) PopScope(i *interpreter.Interpreter, scope string) {
+	t.depth--
+	t.emit(Event{Kind: "pop", File: file(i), Depth: t.depth, Scope: scope}, "} "+scope)
+}
+
+// emit writes e as JSON or text, indented by the depth of e.
+func (t *Tracer) emit(e Event, text string) {
+	if t.json != nil {
+		// Like text, a trace is best effort and write errors are dropped.
+		_ = t.json.Encode(e)
+		return
+	}
+	fmt.Fprintf(t.w, "%s%s\n", strings.Repeat("  ", e.Depth), text)
+}
+
+// file is the file the innermost frame of i runs.
+func file(i *interpreter.Interpreter) string {
+	return i.Stack()[0].File
+}
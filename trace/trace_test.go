@@ -0,0 +1,109 @@
+package trace_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/it-a-me/clavlang/interpreter"
+	"github.com/it-a-me/clavlang/parser"
+	"github.com/it-a-me/clavlang/scanner"
+	"github.com/it-a-me/clavlang/token"
+	"github.com/it-a-me/clavlang/trace"
+)
+
+const program = `fun double(n) {
+  return n * 2;
+}
+{
+  var x = double(3);
+}
+try { [].get(0); } catch (e) { }
+`
+
+func parse(t *testing.T) ([]token.Token, []parser.Stmt) {
+	t.Helper()
+	s := scanner.NewScanner(program)
+	tokens, errs := s.Scan()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	p := parser.NewParser(tokens)
+	stmts, errs := p.Parse()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	return tokens, stmts
+}
+
+func execute(t *testing.T, tracer *trace.Tracer, stmts []parser.Stmt) {
+	t.Helper()
+	i := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard), interpreter.WithTracer(tracer))
+	if err := i.Interpret(stmts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestText(t *testing.T) {
+	tokens, stmts := parse(t)
+	var out strings.Builder
+	tracer := trace.New(&out, false)
+	tracer.Tokens(tokens[:3])
+	tracer.AST(stmts)
+	execute(t, tracer, stmts)
+	want := `1: Fun fun
+1: Identifier double
+1: LeftParen (
+1: (fun double (n) (return (* n 2)))
+4: (block (var x (call double 3)))
+7: (try (block (call (. (list) get) 0)) (catch e))
+line 1: (fun double (n) (return (* n 2)))
+{ block
+  line 5: (var x (call double 3))
+    double => <fn double>
+  { call double
+    line 2: (return (* n 2))
+      n => 3
+      (* n 2) => 6
+  } call double
+    (call double 3) => 6
+} block
+line 7: (try (block (call (. (list) get) 0)) (catch e))
+{ block
+  line 7: (call (. (list) get) 0)
+    (list) => []
+    (. (list) get) => <native fn list.get>
+} block
+{ catch e
+  { block
+  } block
+} catch e
+`
+	if out.String() != want {
+		t.Errorf("trace:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	_, stmts := parse(t)
+	var out strings.Builder
+	execute(t, trace.New(&out, true), stmts)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	events := make([]trace.Event, len(lines))
+	for j, line := range lines {
+		if err := json.Unmarshal([]byte(line), &events[j]); err != nil {
+			t.Fatalf("line %d: %v: %s", j+1, err, line)
+		}
+	}
+	if len(events) != 21 {
+		t.Fatalf("%d events:\n%s", len(events), out.String())
+	}
+	want := trace.Event{Kind: "expression", Line: 2, Depth: 2, Node: "(* n 2)", Value: "6"}
+	if events[7] != want {
+		t.Errorf("event %+v, want %+v", events[7], want)
+	}
+	if events[len(events)-1].Kind != "pop" || events[len(events)-1].Depth != 0 {
+		t.Errorf("trace ends with %+v", events[len(events)-1])
+	}
+}
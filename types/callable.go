@@ -0,0 +1,51 @@
+package types
+
+// Variadic is the arity of callables that accept any number of arguments.
+const Variadic = -1
+
+type Callable interface {
+	ClavType
+	Arity() int
+	Call(args []ClavType) (ClavType, error)
+}
+
+// Foreign is embedded by clav values that are defined outside this package,
+// such as functions declared by a script.
+type Foreign struct{}
+
+func (Foreign) clav() {}
+
+// Object is implemented by values that have properties read with '.'.
+type Object interface {
+	ClavType
+	Property(name string) (ClavType, bool)
+}
+
+// NativeFunction is a callable implemented in Go.
+type NativeFunction struct {
+	Name   string
+	Params int
+	Fn     func(args []ClavType) (ClavType, error)
+}
+
+// Module is a namespace of values, such as a native standard library module.
+type Module struct {
+	Name    string
+	Members map[string]ClavType
+}
+
+func (NativeFunction) clav() {}
+func (f NativeFunction) String() string {
+	return "<native fn " + f.Name + ">"
+}
+func (f NativeFunction) Arity() int {
+	return f.Params
+}
+func (f NativeFunction) Call(args []ClavType) (ClavType, error) {
+	return f.Fn(args)
+}
+
+func (Module) clav() {}
+func (m Module) String() string {
+	return "<module " + m.Name + ">"
+}
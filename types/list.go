@@ -0,0 +1,48 @@
+package types
+
+import "strings"
+
+// List is an ordered, mutable sequence of values. Lists are shared by
+// reference, so they are always handled as *List.
+type List struct {
+	Elements []ClavType
+}
+
+func NewList(elements ...ClavType) *List {
+	return &List{Elements: elements}
+}
+
+func (*List) clav() {}
+
+// String shows a list that contains itself as [...] where it recurs.
+func (l *List) String() string {
+	return l.format(map[ClavType]bool{})
+}
+
+// format renders l, with seen holding the containers l is nested in.
+func (l *List) format(seen map[ClavType]bool) string {
+	if seen[l] {
+		return "[...]"
+	}
+	seen[l] = true
+	defer delete(seen, l)
+	parts := make([]string, len(l.Elements))
+	for i, e := range l.Elements {
+		parts[i] = formatNested(e, seen)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// formatNested renders a value held by a container, passing seen on to
+// containers so that cycles are cut short.
+func formatNested(value ClavType, seen map[ClavType]bool) string {
+	switch v := value.(type) {
+	case nil:
+		return Nil{}.String()
+	case *List:
+		return v.format(seen)
+	case *Map:
+		return v.format(seen)
+	}
+	return value.String()
+}
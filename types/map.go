@@ -0,0 +1,68 @@
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// Map associates string keys with values, remembering insertion order. Maps
+// are shared by reference, so they are always handled as *Map.
+type Map struct {
+	keys   []string
+	values map[string]ClavType
+}
+
+func NewMap() *Map {
+	return &Map{values: map[string]ClavType{}}
+}
+
+func (m *Map) Get(key string) (ClavType, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+func (m *Map) Set(key string, value ClavType) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *Map) Delete(key string) bool {
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k string) bool { return k == key })
+	return true
+}
+
+// Keys returns the keys of m in insertion order.
+func (m *Map) Keys() []string {
+	return slices.Clone(m.keys)
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+func (*Map) clav() {}
+
+// String shows a map that contains itself as {...} where it recurs.
+func (m *Map) String() string {
+	return m.format(map[ClavType]bool{})
+}
+
+// format renders m, with seen holding the containers m is nested in.
+func (m *Map) format(seen map[ClavType]bool) string {
+	if seen[m] {
+		return "{...}"
+	}
+	seen[m] = true
+	defer delete(seen, m)
+	parts := make([]string, len(m.keys))
+	for i, k := range m.keys {
+		parts[i] = k + ": " + formatNested(m.values[k], seen)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}